@@ -0,0 +1,8 @@
+// Package api embeds the OpenAPI document that describes, and at runtime
+// validates, the HTTP API.
+package api
+
+import _ "embed"
+
+//go:embed openapi.yaml
+var OpenAPI []byte
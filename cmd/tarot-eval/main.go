@@ -0,0 +1,182 @@
+// Command tarot-eval runs a corpus of readings through one or more
+// interpreter configurations and writes a comparison report. With only the
+// template interpreter it needs no network, so it can run in CI.
+//
+//	tarot-eval --candidate qwen=openrouter:qwen/qwen3-4b:free \
+//	  --candidate qwen-draft=openrouter:qwen/qwen3-4b:free --prompts qwen-draft=./prompts-draft \
+//	  --json eval.json --markdown eval.md
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/randomtoy/taas-go/internal/adapters/decks"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/prompts"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/provider"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/template"
+	"github.com/randomtoy/taas-go/internal/config"
+	"github.com/randomtoy/taas-go/internal/eval"
+	"github.com/randomtoy/taas-go/internal/routing"
+)
+
+// pairs collects repeated name=value flags in order.
+type pairs [][2]string
+
+func (p *pairs) String() string { return fmt.Sprint(*p) }
+
+func (p *pairs) Set(v string) error {
+	name, value, ok := strings.Cut(v, "=")
+	if !ok || name == "" || value == "" {
+		return fmt.Errorf("want name=value")
+	}
+	*p = append(*p, [2]string{name, value})
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "tarot-eval:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	var candidates, promptDirs pairs
+	fs := flag.NewFlagSet("tarot-eval", flag.ContinueOnError)
+	fs.Var(&candidates, "candidate", "name=provider:model or name=template; repeatable (default template=template)")
+	fs.Var(&promptDirs, "prompts", "name=dir: prompt templates for the named candidate (default PROMPTS_DIR)")
+	corpusPath := fs.String("corpus", "", "YAML corpus file (default: the built-in corpus)")
+	configFile := fs.String("config", "", "service config file for provider settings (default CONFIG_FILE)")
+	jsonPath := fs.String("json", "", "write the full JSON report to this file")
+	markdownPath := fs.String("markdown", "-", "write the Markdown report to this file; - for stdout")
+	minScore := fs.Float64("min-score", 0, "exit with an error if any candidate scores below this")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if len(candidates) == 0 {
+		candidates = pairs{{"template", routing.TemplateProvider}}
+	}
+
+	corpus := eval.DefaultCorpus()
+	if *corpusPath != "" {
+		var err error
+		if corpus, err = eval.LoadCorpus(*corpusPath); err != nil {
+			return err
+		}
+	}
+
+	cands, err := buildCandidates(candidates, promptDirs, *configFile)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report := eval.Run(ctx, decks.NewEmbeddedStore(), corpus, cands, eval.DefaultChecks())
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if *jsonPath != "" {
+		if err := writeFile(*jsonPath, stdout, report.WriteJSON); err != nil {
+			return err
+		}
+	}
+	if *markdownPath != "" {
+		if err := writeFile(*markdownPath, stdout, report.WriteMarkdown); err != nil {
+			return err
+		}
+	}
+
+	var low []string
+	for _, c := range report.Candidates {
+		if c.Summary.Score < *minScore {
+			low = append(low, fmt.Sprintf("%s (%.2f)", c.Name, c.Summary.Score))
+		}
+	}
+	if len(low) > 0 {
+		return fmt.Errorf("below --min-score %.2f: %s", *minScore, strings.Join(low, ", "))
+	}
+	return nil
+}
+
+// buildCandidates creates an interpreter per candidate. Provider settings
+// come from the service configuration; the candidates are loaded as its
+// routing targets so that only their providers need credentials.
+func buildCandidates(candidates, promptDirs pairs, configFile string) ([]eval.Candidate, error) {
+	targets := make([]string, len(candidates))
+	for i, c := range candidates {
+		targets[i] = c[0] + "=" + c[1]
+	}
+	args := []string{
+		"--routing-targets", strings.Join(targets, ","),
+		"--routing-order=", "--routing-policy=fallback", "--routing-weights=",
+		"--routing-by-lang=", "--routing-by-cards=",
+	}
+	if configFile != "" {
+		args = append([]string{"--config", configFile}, args...)
+	}
+	cfg, err := config.Load(args)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	for _, p := range promptDirs {
+		if !slices.ContainsFunc(candidates, func(c [2]string) bool { return c[0] == p[0] }) {
+			return nil, fmt.Errorf("--prompts names unknown candidate %q", p[0])
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	out := make([]eval.Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		name, spec := c[0], cfg.Routing.Targets[c[0]]
+		if spec.Provider == routing.TemplateProvider {
+			out = append(out, eval.Candidate{Name: name, Interpreter: template.NewInterpreter()})
+			continue
+		}
+		dir := cfg.PromptsDir
+		for _, p := range promptDirs {
+			if p[0] == name {
+				dir = p[1]
+			}
+		}
+		promptSet := prompts.Default()
+		if dir != "" {
+			if promptSet, err = prompts.Load(dir); err != nil {
+				return nil, fmt.Errorf("candidate %s: load prompt templates from %s: %w", name, dir, err)
+			}
+		}
+		out = append(out, eval.Candidate{Name: name, Interpreter: provider.New(cfg, spec.Provider, spec.Model, nil, promptSet, logger)})
+	}
+	return out, nil
+}
+
+func writeFile(path string, stdout io.Writer, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -2,20 +2,38 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"math/rand/v2"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/randomtoy/taas-go/api"
 	"github.com/randomtoy/taas-go/internal/adapters/decks"
 	httpadapter "github.com/randomtoy/taas-go/internal/adapters/http"
-	"github.com/randomtoy/taas-go/internal/adapters/llm/openrouter"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/prompts"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/provider"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/template"
+	"github.com/randomtoy/taas-go/internal/adapters/readings"
 	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/budget"
 	"github.com/randomtoy/taas-go/internal/config"
+	"github.com/randomtoy/taas-go/internal/guardrails"
+	"github.com/randomtoy/taas-go/internal/health"
+	"github.com/randomtoy/taas-go/internal/jobs"
+	"github.com/randomtoy/taas-go/internal/openapi"
+	"github.com/randomtoy/taas-go/internal/ports"
+	"github.com/randomtoy/taas-go/internal/ratelimit"
+	"github.com/randomtoy/taas-go/internal/reload"
+	"github.com/randomtoy/taas-go/internal/routing"
 )
 
 // stdRNG delegates to math/rand/v2 (auto-seeded).
@@ -24,42 +42,132 @@ type stdRNG struct{}
 func (stdRNG) Intn(n int) int { return rand.IntN(n) }
 
 func main() {
-	cfg, err := config.Load()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
 		slog.Error("failed to load config", "error", err)
 		os.Exit(1)
 	}
+	if cfg.PrintConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			slog.Error("failed to print config", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel}))
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.LogLevel)
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
 	slog.SetDefault(logger)
 
 	deckStore := decks.NewEmbeddedStore()
+	tracker := budget.NewTracker(cfg.Budget)
+	if cfg.Budget.Uncapped() {
+		logger.Warn("per-key budgets are set without a global budget; X-Api-Key is not authenticated, so total LLM spend is not capped")
+	}
+	limiter := ratelimit.NewLimiter(cfg.RateLimits)
+	llmSlots := ratelimit.NewConcurrency(cfg.LLMMaxInFlight)
 
-	llmClient := openrouter.NewClient(
-		&http.Client{Timeout: cfg.LLMTimeout},
-		cfg.OpenRouterAPIKey,
-		cfg.OpenRouterBaseURL,
-		cfg.LLMModel,
-		cfg.LLMFallbackModels,
+	chain, primaryLLM, err := newInterpreter(cfg, tracker, llmSlots, logger)
+	if err != nil {
+		logger.Error("failed to build interpreter", "error", err)
+		os.Exit(1)
+	}
+	// Reloads swap these; requests in flight keep what they started with.
+	interpreter := reload.NewInterpreter(chain)
+	var currentLLM atomic.Pointer[provider.Client]
+	currentLLM.Store(&primaryLLM)
+
+	reloader := reload.NewReloader(cfg,
+		func() (config.Config, error) { return config.Load(os.Args[1:]) },
+		func(next config.Config) error {
+			chain, primaryLLM, err := newInterpreter(next, tracker, llmSlots, logger)
+			if err != nil {
+				return err
+			}
+			logLevel.Set(next.LogLevel)
+			tracker.SetLimits(next.Budget)
+			limiter.SetLimits(next.RateLimits)
+			llmSlots.SetMax(next.LLMMaxInFlight)
+			currentLLM.Store(&primaryLLM)
+			interpreter.Swap(chain)
+			return nil
+		},
 		logger,
 	)
 
-	svc := app.NewTarotService(deckStore, llmClient, stdRNG{}, cfg.LLMModel)
+	svc := app.NewTarotService(deckStore, interpreter, stdRNG{}, cfg.LLMModel,
+		app.WithReadings(readings.NewMemoryStore(cfg.ReadingTTL, cfg.ReadingMaxEntries), cfg.MaxFollowUps),
+		app.WithBatchConcurrency(cfg.BatchConcurrency),
+	)
 
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	e.HTTPErrorHandler = httpadapter.ProblemErrorHandler
+	e.IPExtractor = httpadapter.IPExtractor(cfg.TrustedProxies)
 
 	e.Use(httpadapter.RequestIDMiddleware())
 	e.Use(httpadapter.LoggingMiddleware(logger))
+	e.Use(httpadapter.SecurityHeadersMiddleware(cfg.HSTSMaxAge))
+	e.Use(httpadapter.CORSMiddleware(httpadapter.CORSConfig{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}))
+	e.Use(httpadapter.RateLimitMiddleware(limiter))
+
+	spec, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		logger.Error("failed to load OpenAPI document", "error", err)
+		os.Exit(1)
+	}
+	var onResponseMismatch func(echo.Context, string, []string)
+	if cfg.ValidateResponses {
+		onResponseMismatch = func(c echo.Context, operationID string, problems []string) {
+			logger.Error("response does not match OpenAPI document",
+				"request_id", c.Get("request_id"), "operation", operationID, "problems", problems)
+		}
+	}
+	e.Use(httpadapter.OpenAPIValidator(spec, int64(cfg.MaxBodyBytes), onResponseMismatch))
+	if cfg.IdempotencyTTL > 0 {
+		e.Use(httpadapter.IdempotencyMiddleware(httpadapter.NewIdempotencyStore(cfg.IdempotencyTTL)))
+	}
+
+	var webhook *jobs.Webhook
+	if cfg.WebhookSecret != "" {
+		webhook = jobs.NewWebhook(jobs.NewClient(cfg.WebhookTimeout), cfg.WebhookSecret, cfg.WebhookMaxAttempts, time.Second, cfg.WebhookAllowedHosts)
+	}
+	queue := jobs.NewQueue(cfg.JobWorkers, cfg.JobQueueDepth, cfg.JobTTL, webhook, logger)
 
 	handler := httpadapter.NewHandler(svc)
 	handler.Register(e)
+	httpadapter.NewJobsHandler(svc, queue).Register(e)
+	httpadapter.NewBatchHandler(svc, spec, limiter, cfg.BatchMaxItems).Register(e)
+	httpadapter.NewAdminHandler(cfg.AdminToken, tracker, reloader).Register(e)
+
+	monitor := health.NewMonitor(cfg.HealthInterval, cfg.HealthTimeout, logger)
+	monitor.Register("decks", deckStore)
+	monitor.RegisterOptional("llm", health.CheckerFunc(func(ctx context.Context) error {
+		return (*currentLLM.Load()).Check(ctx)
+	}))
+	httpadapter.NewHealthHandler(monitor).Register(e)
 
 	// Graceful shutdown.
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	go monitor.Run(ctx)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go reloader.Watch(ctx, hup)
+
 	go func() {
 		logger.Info("starting server", "addr", cfg.HTTPAddr)
 		if err := e.Start(cfg.HTTPAddr); err != nil && err != http.ErrServerClosed {
@@ -71,9 +179,77 @@ func main() {
 	<-ctx.Done()
 	logger.Info("shutting down")
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		logger.Error("shutdown error", "error", err)
 	}
+	// Finish accepted jobs and their webhooks before exiting.
+	if err := queue.Shutdown(shutdownCtx); err != nil {
+		logger.Error("job queue did not drain", "error", err)
+	}
+}
+
+// newInterpreter builds the interpretation chain from the reloadable LLM
+// settings, returning the primary LLM client or router too for health
+// checks. Every LLM call takes one of llmSlots.
+func newInterpreter(cfg config.Config, tracker *budget.Tracker, llmSlots *ratelimit.Concurrency, logger *slog.Logger) (ports.Interpreter, provider.Client, error) {
+	promptSet := prompts.Default()
+	if cfg.PromptsDir != "" {
+		var err error
+		if promptSet, err = prompts.Load(cfg.PromptsDir); err != nil {
+			return nil, nil, fmt.Errorf("load prompt templates from %s: %w", cfg.PromptsDir, err)
+		}
+	}
+	logger.Info("prompt templates loaded", "version", promptSet.Version())
+
+	var client provider.Client
+	var primary ports.Interpreter
+	if cfg.Routing.Enabled() {
+		router, err := newRouter(cfg, promptSet, llmSlots, logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("build router: %w", err)
+		}
+		client, primary = router, router
+	} else {
+		client = provider.New(cfg, cfg.LLMProvider, cfg.LLMModel, cfg.LLMFallbackModels, promptSet, logger)
+		primary = llmSlots.Wrap(client)
+	}
+
+	interpreter := guardrails.NewGuard(
+		guardrails.NewRuleClassifier(guardrails.DefaultRules()),
+		guardrails.NewScanner(guardrails.DefaultForbidden()),
+		budget.NewGuard(tracker, primary, degradedInterpreter(cfg, promptSet, llmSlots, logger), logger),
+		template.NewInterpreter(),
+		logger,
+	)
+	return interpreter, client, nil
+}
+
+// degradedInterpreter returns what serves requests once the LLM budget is
+// used up, or nil to reject them.
+func degradedInterpreter(cfg config.Config, promptSet *prompts.Set, llmSlots *ratelimit.Concurrency, logger *slog.Logger) ports.Interpreter {
+	switch cfg.BudgetAction {
+	case budget.ActionDowngrade:
+		return llmSlots.Wrap(provider.New(cfg, cfg.LLMProvider, cfg.BudgetDowngradeModel, nil, promptSet, logger))
+	case budget.ActionTemplate:
+		return template.NewInterpreter()
+	default:
+		return nil
+	}
+}
+
+// newRouter builds a target for every configured routing target. LLM
+// targets take one of llmSlots per call; the template target never waits.
+func newRouter(cfg config.Config, promptSet *prompts.Set, llmSlots *ratelimit.Concurrency, logger *slog.Logger) (*routing.Router, error) {
+	targets := make(map[string]routing.Target, len(cfg.Routing.Targets))
+	for name, spec := range cfg.Routing.Targets {
+		if spec.Provider == routing.TemplateProvider {
+			targets[name] = routing.Target{Interpreter: template.NewInterpreter()}
+			continue
+		}
+		client := provider.New(cfg, spec.Provider, spec.Model, nil, promptSet, logger)
+		targets[name] = routing.Target{Interpreter: llmSlots.Wrap(client), Checker: client}
+	}
+	return routing.NewRouter(cfg.Routing, targets, stdRNG{}, logger)
 }
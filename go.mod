@@ -2,7 +2,11 @@ module github.com/randomtoy/taas-go
 
 go 1.25.0
 
-require github.com/labstack/echo/v4 v4.15.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/labstack/echo/v4 v4.15.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/labstack/gommon v0.4.2 // indirect
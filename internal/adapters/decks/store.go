@@ -61,3 +61,9 @@ func (s *EmbeddedStore) GetDeck(_ context.Context, deckID string) (domain.Deck,
 	}
 	return deck, nil
 }
+
+// Check reports whether the embedded decks loaded.
+func (s *EmbeddedStore) Check(_ context.Context) error {
+	s.once.Do(s.init)
+	return s.err
+}
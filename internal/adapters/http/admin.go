@@ -0,0 +1,61 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/internal/budget"
+	"github.com/randomtoy/taas-go/internal/reload"
+)
+
+// AdminHandler serves operator endpoints under /admin. They are only
+// registered when an admin token is configured.
+type AdminHandler struct {
+	token    string
+	budget   *budget.Tracker
+	reloader *reload.Reloader
+}
+
+func NewAdminHandler(token string, tracker *budget.Tracker, reloader *reload.Reloader) *AdminHandler {
+	return &AdminHandler{token: token, budget: tracker, reloader: reloader}
+}
+
+func (h *AdminHandler) Register(e *echo.Echo) {
+	if h.token == "" {
+		return
+	}
+	g := e.Group("/admin", AdminAuthMiddleware(h.token))
+	g.GET("/budget", h.Budget)
+	g.POST("/reload", h.Reload)
+}
+
+// Budget returns current LLM spend globally and per API key fingerprint.
+func (h *AdminHandler) Budget(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.budget.Snapshot())
+}
+
+// Reload re-reads the configuration, as SIGHUP does, and reports which
+// changes were applied and which need a restart.
+func (h *AdminHandler) Reload(c echo.Context) error {
+	res, err := h.reloader.Reload()
+	if err != nil {
+		return problem(c, codeInvalidConfig, err.Error())
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// AdminAuthMiddleware requires "Authorization: Bearer <token>".
+func AdminAuthMiddleware(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			got, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				return problem(c, codeUnauthorized, "a valid admin bearer token is required")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/openapi"
+	"github.com/randomtoy/taas-go/internal/ratelimit"
+)
+
+const mimeNDJSON = "application/x-ndjson"
+
+// BatchHandler serves POST /v1/readings/batch. Items are validated
+// against the BatchItem schema of spec one by one, so that an invalid item
+// fails on its own. Every valid item takes a token from limiter, as a
+// request of its own would: the request's token pays for the first.
+type BatchHandler struct {
+	svc      *app.TarotService
+	spec     *openapi.Spec
+	limiter  *ratelimit.Limiter
+	maxItems int
+}
+
+func NewBatchHandler(svc *app.TarotService, spec *openapi.Spec, limiter *ratelimit.Limiter, maxItems int) *BatchHandler {
+	return &BatchHandler{svc: svc, spec: spec, limiter: limiter, maxItems: maxItems}
+}
+
+func (h *BatchHandler) Register(e *echo.Echo) {
+	e.POST("/v1/readings/batch", h.ReadBatch)
+}
+
+// ReadBatch generates many readings in one call. Each item succeeds or
+// fails on its own. Results are returned together once all are done, or
+// streamed as NDJSON in completion order when the client asks for
+// application/x-ndjson or passes ?stream=true.
+func (h *BatchHandler) ReadBatch(c echo.Context) error {
+	var body struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := c.Bind(&body); err != nil {
+		if ok, p := bodyTooLarge(c, err); ok {
+			return p
+		}
+		return problem(c, codeInvalidRequestBody, "the body must be a JSON object")
+	}
+	if len(body.Items) == 0 {
+		return problem(c, codeBatchEmpty, "items must not be empty")
+	}
+	if len(body.Items) > h.maxItems {
+		return problem(c, codeBatchTooLarge, fmt.Sprintf("a batch may contain at most %d items", h.maxItems))
+	}
+
+	stream := strings.Contains(c.Request().Header.Get(echo.HeaderAccept), mimeNDJSON)
+	if raw := c.QueryParam("stream"); raw != "" {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid(c, []FieldError{{Field: "stream", Code: codeInvalidParameter, Detail: "stream must be true or false"}})
+		}
+		stream = b
+	}
+
+	requestID, _ := c.Get("request_id").(string)
+	apiKey := c.Request().Header.Get(headerAPIKey)
+
+	out := newBatchWriter(c, stream, len(body.Items))
+
+	// Invalid and rate-limited items fail up front; the rest are
+	// interpreted.
+	items := make([]BatchItem, len(body.Items))
+	var reqs []app.ReadSpreadRequest
+	var indices []int
+	for i, raw := range body.Items {
+		errs := h.validateItem(raw, &items[i])
+		if len(errs) > 0 {
+			p := validationProblem(errs, requestID)
+			out.write(BatchItemResult{Index: i, ID: items[i].ID, Status: p.Status, Error: &p})
+			continue
+		}
+		if len(reqs) > 0 {
+			if d := h.limiter.Allow(c.Path(), c.RealIP()); !d.Allowed {
+				p := newProblem(codeRateLimited, fmt.Sprintf("rate limit reached; retry this item in %d s", ratelimit.RetryAfterSeconds(d.RetryAfter)), requestID)
+				out.write(BatchItemResult{Index: i, ID: items[i].ID, Status: p.Status, Error: &p})
+				continue
+			}
+		}
+		reqs = append(reqs, items[i].toApp(apiKey))
+		indices = append(indices, i)
+	}
+
+	h.svc.ReadSpreadBatch(c.Request().Context(), reqs, func(r app.BatchResult) {
+		i := indices[r.Index]
+		res := BatchItemResult{Index: i, ID: items[i].ID, Status: http.StatusOK}
+		if r.Err != nil {
+			p := problemFor(r.Err, requestID)
+			res.Status, res.Error = p.Status, &p
+		} else {
+			resp := toResponse(r.Response, requestID)
+			res.Result = &resp
+		}
+		out.write(res)
+	})
+
+	return out.finish()
+}
+
+// validateItem checks one raw item against the spec and decodes it into
+// item.
+func (h *BatchHandler) validateItem(raw json.RawMessage, item *BatchItem) []FieldError {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return []FieldError{{Code: codeInvalidField, Detail: "item is not valid JSON"}}
+	}
+	errs := toFieldErrors(h.spec.CheckSchema(h.spec.Schema("BatchItem"), doc))
+	if len(errs) > 0 {
+		return errs
+	}
+	if err := json.Unmarshal(raw, item); err != nil {
+		return []FieldError{{Code: codeInvalidField, Detail: "item does not match the BatchItem schema"}}
+	}
+	if item.WebhookURL != "" {
+		return []FieldError{{Field: "webhook_url", Code: codeInvalidWebhookURL, Detail: "webhook_url is not supported in batches"}}
+	}
+	return nil
+}
+
+// batchWriter either streams each result as an NDJSON line or collects
+// them for a single JSON response ordered by index.
+type batchWriter struct {
+	c       echo.Context
+	stream  bool
+	started bool
+	results []BatchItemResult
+	summary BatchResponse
+}
+
+func newBatchWriter(c echo.Context, stream bool, n int) *batchWriter {
+	return &batchWriter{c: c, stream: stream, results: make([]BatchItemResult, n)}
+}
+
+func (w *batchWriter) write(r BatchItemResult) {
+	if r.Error == nil {
+		w.summary.Succeeded++
+	} else {
+		w.summary.Failed++
+	}
+	if !w.stream {
+		w.results[r.Index] = r
+		return
+	}
+
+	res := w.c.Response()
+	if !w.started {
+		res.Header().Set(echo.HeaderContentType, mimeNDJSON)
+		res.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+	// The status line is sent; a client that went away only loses results.
+	_ = json.NewEncoder(res).Encode(r)
+	res.Flush()
+}
+
+func (w *batchWriter) finish() error {
+	if w.stream {
+		return nil
+	}
+	w.summary.Results = w.results
+	return w.c.JSON(http.StatusOK, w.summary)
+}
@@ -0,0 +1,248 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/api"
+	"github.com/randomtoy/taas-go/internal/adapters/decks"
+	httpadapter "github.com/randomtoy/taas-go/internal/adapters/http"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/template"
+	"github.com/randomtoy/taas-go/internal/adapters/readings"
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/budget"
+	"github.com/randomtoy/taas-go/internal/config"
+	"github.com/randomtoy/taas-go/internal/health"
+	"github.com/randomtoy/taas-go/internal/jobs"
+	"github.com/randomtoy/taas-go/internal/openapi"
+	"github.com/randomtoy/taas-go/internal/ratelimit"
+	"github.com/randomtoy/taas-go/internal/reload"
+	"github.com/randomtoy/taas-go/internal/routing"
+)
+
+type seqRNG struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (r *seqRNG) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n++
+	return r.n % n
+}
+
+const adminToken = "secret"
+
+// newContractServer runs the full HTTP stack with the template interpreter,
+// behind a router so responses carry route meta, and reports every
+// response that does not match the OpenAPI document.
+func newContractServer(t *testing.T, monitor *health.Monitor, reloader *reload.Reloader) *httptest.Server {
+	t.Helper()
+	spec, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	router, err := routing.NewRouter(
+		routing.Config{Targets: map[string]routing.Spec{"canned": {Provider: routing.TemplateProvider}}, Policy: routing.PolicyFallback},
+		map[string]routing.Target{"canned": {Interpreter: template.NewInterpreter()}},
+		&seqRNG{}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := app.NewTarotService(decks.NewEmbeddedStore(), router, &seqRNG{}, "template",
+		app.WithReadings(readings.NewMemoryStore(time.Hour, 100), 5),
+	)
+	queue := jobs.NewQueue(1, 10, time.Hour, nil, logger)
+	t.Cleanup(func() { _ = queue.Shutdown(t.Context()) })
+
+	e := echo.New()
+	e.HTTPErrorHandler = httpadapter.ProblemErrorHandler
+	e.Use(httpadapter.RequestIDMiddleware())
+	limiter := ratelimit.NewLimiter(ratelimit.Limits{
+		Routes: map[string]ratelimit.Rate{"/v1/styles": {N: 1, Period: time.Hour}},
+	})
+	e.Use(httpadapter.RateLimitMiddleware(limiter))
+	e.Use(httpadapter.OpenAPIValidator(spec, 1<<10, func(c echo.Context, operationID string, problems []string) {
+		t.Errorf("%s %s (%s): response does not match the spec:\n%s",
+			c.Request().Method, c.Request().URL, operationID, strings.Join(problems, "\n"))
+	}))
+	e.Use(httpadapter.IdempotencyMiddleware(httpadapter.NewIdempotencyStore(time.Hour)))
+	httpadapter.NewHandler(svc).Register(e)
+	httpadapter.NewJobsHandler(svc, queue).Register(e)
+	httpadapter.NewBatchHandler(svc, spec, limiter, 10).Register(e)
+	httpadapter.NewAdminHandler(adminToken, budget.NewTracker(budget.Limits{}), reloader).Register(e)
+	httpadapter.NewHealthHandler(monitor).Register(e)
+
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type call struct {
+	method, path, body string
+	header             map[string]string
+}
+
+func (c call) do(t *testing.T, srv *httptest.Server) (*http.Response, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(c.method, srv.URL+c.path, strings.NewReader(c.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for k, v := range c.header {
+		req.Header.Set(k, v)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+
+	var doc map[string]any
+	_ = json.Unmarshal(raw, &doc)
+	return resp, doc
+}
+
+// TestContract exercises every operation in api/openapi.yaml, checking
+// each response against the document.
+func TestContract(t *testing.T) {
+	var llmErr error
+	monitor := health.NewMonitor(time.Hour, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	monitor.Register("decks", decks.NewEmbeddedStore())
+	monitor.RegisterOptional("llm", health.CheckerFunc(func(context.Context) error { return llmErr }))
+	var reloadErr error
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := cfg
+	next.HTTPAddr, next.LLMModel = ":9090", "next"
+	reloader := reload.NewReloader(cfg,
+		func() (config.Config, error) { return next, reloadErr },
+		func(config.Config) error { return nil },
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+	srv := newContractServer(t, monitor, reloader)
+	spec, _ := openapi.Load(api.OpenAPI)
+	covered := map[string]bool{}
+
+	expect := func(c call, status int, code string) map[string]any {
+		t.Helper()
+		resp, doc := c.do(t, srv)
+		if op, _ := spec.Find(c.method, strings.Split(c.path, "?")[0]); op != nil {
+			covered[op.ID] = true
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("%s %s: status %d, want %d: %v", c.method, c.path, resp.StatusCode, status, doc)
+		}
+		if code != "" {
+			if ct := resp.Header.Get(echo.HeaderContentType); ct != "application/problem+json" {
+				t.Errorf("%s %s: Content-Type %q", c.method, c.path, ct)
+			}
+			if doc["code"] != code {
+				t.Errorf("%s %s: code %v, want %s", c.method, c.path, doc["code"], code)
+			}
+		}
+		return doc
+	}
+
+	expect(call{method: http.MethodGet, path: "/healthz"}, http.StatusOK, "")
+	expect(call{method: http.MethodGet, path: "/livez"}, http.StatusOK, "")
+	expect(call{method: http.MethodGet, path: "/readyz"}, http.StatusServiceUnavailable, "")
+	monitor.CheckAll(t.Context())
+	expect(call{method: http.MethodGet, path: "/readyz"}, http.StatusOK, "")
+	llmErr = errors.New("upstream status 401")
+	monitor.CheckAll(t.Context())
+	ready := expect(call{method: http.MethodGet, path: "/readyz"}, http.StatusOK, "")
+	if llm, _ := ready["components"].(map[string]any)["llm"].(map[string]any); llm["status"] != string(health.StatusFailing) || ready["degraded"] != true {
+		t.Errorf("llm component not reported as failing: %v", ready)
+	}
+	expect(call{method: http.MethodGet, path: "/v1/styles"}, http.StatusOK, "")
+	expect(call{method: http.MethodGet, path: "/v1/styles"}, http.StatusTooManyRequests, "rate_limited")
+
+	reading := expect(call{method: http.MethodGet, path: "/v1/tarot?q=work&n=3&style=poetic"}, http.StatusOK, "")
+	expect(call{method: http.MethodGet, path: "/v1/tarot?n=11"}, http.StatusBadRequest, "invalid_n")
+	expect(call{method: http.MethodGet, path: "/v1/tarot?style=grim"}, http.StatusBadRequest, "unknown_style")
+	expect(call{method: http.MethodGet, path: "/v1/tarot?length=epic"}, http.StatusBadRequest, "invalid_length")
+	seeded := expect(call{method: http.MethodGet, path: "/v1/tarot?n=5&length=short&seed=42"}, http.StatusOK, "")
+	if again := expect(call{method: http.MethodGet, path: "/v1/tarot?n=5&length=short&seed=42"}, http.StatusOK, ""); !reflect.DeepEqual(again["cards"], seeded["cards"]) {
+		t.Errorf("same seed drew different cards: %v, %v", seeded["cards"], again["cards"])
+	}
+	expect(call{method: http.MethodGet, path: "/v1/tarot?deck=nope"}, http.StatusNotFound, "deck_not_found")
+
+	if meta, _ := reading["meta"].(map[string]any); meta["route"] == nil {
+		t.Errorf("reading has no route meta: %v", reading["meta"])
+	}
+	id, _ := reading["reading_id"].(string)
+	expect(call{method: http.MethodGet, path: "/v1/readings/" + id}, http.StatusOK, "")
+	expect(call{method: http.MethodGet, path: "/v1/readings/missing"}, http.StatusNotFound, "reading_not_found")
+	expect(call{method: http.MethodPost, path: "/v1/readings/" + id + "/followups", body: `{"question": "And love?"}`}, http.StatusOK, "")
+	expect(call{method: http.MethodPost, path: "/v1/readings/" + id + "/followups", body: `{}`}, http.StatusBadRequest, "question_required")
+	expect(call{method: http.MethodPost, path: "/v1/readings/" + id + "/clarifiers", body: `{"position": 2}`}, http.StatusOK, "")
+	expect(call{method: http.MethodPost, path: "/v1/readings/" + id + "/clarifiers", body: `{"position": 9}`}, http.StatusBadRequest, "invalid_position")
+
+	expect(call{method: http.MethodPost, path: "/v1/readings", body: `{"q": "work", "n": 1}`}, http.StatusOK, "")
+	expect(call{method: http.MethodPost, path: "/v1/readings", body: `{"n": 0}`}, http.StatusBadRequest, "invalid_n")
+	expect(call{method: http.MethodPost, path: "/v1/readings", body: `{"length": "long", "seed": 7}`}, http.StatusOK, "")
+	expect(call{method: http.MethodPost, path: "/v1/readings", body: `{"n":`}, http.StatusBadRequest, "invalid_request_body")
+	large := `{"q": "` + strings.Repeat("a", 2<<10) + `"}`
+	expect(call{method: http.MethodPost, path: "/v1/readings", body: large}, http.StatusRequestEntityTooLarge, "body_too_large")
+	expect(call{method: http.MethodPost, path: "/v1/readings/batch", body: `{"items": [` + large + `]}`}, http.StatusRequestEntityTooLarge, "body_too_large")
+	expect(call{method: http.MethodPost, path: "/v1/readings/batch", body: `{"items": [` + large + `]}`, header: map[string]string{"Idempotency-Key": "large"}}, http.StatusRequestEntityTooLarge, "body_too_large")
+	accepted := expect(call{method: http.MethodPost, path: "/v1/readings?async=true", body: `{}`}, http.StatusAccepted, "")
+	statusURL, _ := accepted["status_url"].(string)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job := expect(call{method: http.MethodGet, path: statusURL}, http.StatusOK, "")
+		if job["status"] == string(jobs.StatusSucceeded) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish: %v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	expect(call{method: http.MethodGet, path: "/v1/jobs/missing"}, http.StatusNotFound, "job_not_found")
+
+	batch := expect(call{method: http.MethodPost, path: "/v1/readings/batch", body: `{"items": [{"id": "a"}, {"id": "b", "n": 42}]}`}, http.StatusOK, "")
+	if batch["succeeded"] != 1.0 || batch["failed"] != 1.0 {
+		t.Errorf("unexpected batch summary: %v", batch)
+	}
+	expect(call{method: http.MethodPost, path: "/v1/readings/batch?stream=true", body: `{"items": [{"n": 2}]}`}, http.StatusOK, "")
+	expect(call{method: http.MethodPost, path: "/v1/readings/batch", body: `{"items": []}`}, http.StatusBadRequest, "batch_empty")
+
+	expect(call{method: http.MethodGet, path: "/admin/budget", header: map[string]string{"Authorization": "Bearer " + adminToken}}, http.StatusOK, "")
+	expect(call{method: http.MethodGet, path: "/admin/budget"}, http.StatusUnauthorized, "unauthorized")
+	reloaded := expect(call{method: http.MethodPost, path: "/admin/reload", header: map[string]string{"Authorization": "Bearer " + adminToken}}, http.StatusOK, "")
+	if applied, _ := reloaded["applied"].([]any); len(applied) != 1 {
+		t.Errorf("unexpected reload result: %v", reloaded)
+	}
+	reloadErr = errors.New("invalid LLM_TIMEOUT \"soon\"")
+	expect(call{method: http.MethodPost, path: "/admin/reload", header: map[string]string{"Authorization": "Bearer " + adminToken}}, http.StatusUnprocessableEntity, "invalid_config")
+
+	for _, op := range spec.Operations() {
+		if !covered[op.ID] {
+			t.Errorf("operation %s (%s %s) is not exercised by the contract test", op.ID, op.Method, op.Path)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CORSConfig controls which browser origins may call the /v1 API. CORS is
+// off when AllowedOrigins is empty.
+type CORSConfig struct {
+	AllowedOrigins   []string // e.g. "https://app.example.com", or "*" for any
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // how long browsers may cache a preflight
+}
+
+// exposedHeaders are the response headers browser scripts may read besides
+// the CORS-safelisted ones.
+var exposedHeaders = strings.Join([]string{
+	headerRequestID,
+	headerIdempotentReplayed,
+	headerRetryAfter,
+	headerRateLimitLimit,
+	headerRateLimitRemaining,
+	headerRateLimitReset,
+	headerRateLimitPolicy,
+	echo.HeaderLocation,
+}, ", ")
+
+// CORSMiddleware answers preflight requests and adds CORS headers to /v1
+// responses for allowed origins. Requests from other origins are served
+// without CORS headers, so browsers refuse to hand the response to the
+// calling page.
+func CORSMiddleware(cfg CORSConfig) echo.MiddlewareFunc {
+	if len(cfg.AllowedOrigins) == 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if !strings.HasPrefix(req.URL.Path, "/v1/") {
+				return next(c)
+			}
+			preflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+			h := c.Response().Header()
+			h.Add(echo.HeaderVary, echo.HeaderOrigin)
+			if preflight {
+				h.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+				h.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			}
+
+			origin := req.Header.Get(echo.HeaderOrigin)
+			if origin == "" || !anyOrigin && !slices.Contains(cfg.AllowedOrigins, strings.ToLower(origin)) {
+				if preflight {
+					return c.NoContent(http.StatusNoContent)
+				}
+				return next(c)
+			}
+
+			if anyOrigin {
+				h.Set(echo.HeaderAccessControlAllowOrigin, "*")
+			} else {
+				h.Set(echo.HeaderAccessControlAllowOrigin, origin)
+			}
+			if cfg.AllowCredentials {
+				h.Set(echo.HeaderAccessControlAllowCredentials, "true")
+			}
+			if !preflight {
+				h.Set(echo.HeaderAccessControlExposeHeaders, exposedHeaders)
+				return next(c)
+			}
+			h.Set(echo.HeaderAccessControlAllowMethods, methods)
+			h.Set(echo.HeaderAccessControlAllowHeaders, headers)
+			if cfg.MaxAge > 0 {
+				h.Set(echo.HeaderAccessControlMaxAge, maxAge)
+			}
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
+
+// SecurityHeadersMiddleware sets headers that stop browsers from sniffing,
+// framing or leaking the referrer of API responses. hsts > 0 also sends
+// Strict-Transport-Security; only enable it when the service is reached
+// over HTTPS.
+func SecurityHeadersMiddleware(hsts time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			h := c.Response().Header()
+			h.Set(echo.HeaderXContentTypeOptions, "nosniff")
+			h.Set(echo.HeaderXFrameOptions, "DENY")
+			h.Set(echo.HeaderContentSecurityPolicy, "default-src 'none'; frame-ancestors 'none'")
+			h.Set(echo.HeaderReferrerPolicy, "no-referrer")
+			h.Set("Cross-Origin-Resource-Policy", "same-origin")
+			if hsts > 0 {
+				h.Set(echo.HeaderStrictTransportSecurity, "max-age="+strconv.Itoa(int(hsts.Seconds())))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	httpadapter "github.com/randomtoy/taas-go/internal/adapters/http"
+)
+
+func newCORSEcho(cfg httpadapter.CORSConfig) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = httpadapter.ProblemErrorHandler
+	e.Use(httpadapter.SecurityHeadersMiddleware(0))
+	e.Use(httpadapter.CORSMiddleware(cfg))
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "OK") }
+	e.GET("/v1/tarot", ok)
+	e.GET("/livez", ok)
+	return e
+}
+
+func corsRequest(e *echo.Echo, method, path, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set(echo.HeaderOrigin, origin)
+	}
+	if method == http.MethodOptions {
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+		req.Header.Set(echo.HeaderAccessControlRequestHeaders, "x-api-key")
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	e := newCORSEcho(httpadapter.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "X-Api-Key"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	rec := corsRequest(e, http.MethodOptions, "/v1/tarot", "https://app.example.com")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight: %d", rec.Code)
+	}
+	h := rec.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		h.Get("Access-Control-Allow-Methods") != "GET, POST" ||
+		h.Get("Access-Control-Allow-Headers") != "Content-Type, X-Api-Key" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("unexpected preflight headers: %v", h)
+	}
+
+	rec = corsRequest(e, http.MethodGet, "/v1/tarot", "https://app.example.com")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("actual request: %d %v", rec.Code, rec.Header())
+	}
+	if rec.Header().Get("Access-Control-Expose-Headers") == "" {
+		t.Error("no exposed headers")
+	}
+
+	rec = corsRequest(e, http.MethodOptions, "/v1/tarot", "https://evil.example.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed origin got CORS headers: %v", rec.Header())
+	}
+	rec = corsRequest(e, http.MethodGet, "/v1/tarot", "https://evil.example.com")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed origin: %d %v", rec.Code, rec.Header())
+	}
+
+	rec = corsRequest(e, http.MethodGet, "/livez", "https://app.example.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("probe got CORS headers: %v", rec.Header())
+	}
+}
+
+func TestCORSMiddleware_AnyOrigin(t *testing.T) {
+	e := newCORSEcho(httpadapter.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+
+	rec := corsRequest(e, http.MethodGet, "/v1/tarot", "https://anywhere.example.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestCORSMiddleware_Disabled(t *testing.T) {
+	e := newCORSEcho(httpadapter.CORSConfig{})
+
+	rec := corsRequest(e, http.MethodGet, "/v1/tarot", "https://app.example.com")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("CORS disabled: %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = httpadapter.ProblemErrorHandler
+	e.Use(httpadapter.SecurityHeadersMiddleware(365 * 24 * time.Hour))
+
+	rec := corsRequest(e, http.MethodGet, "/v1/missing", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	for name, want := range map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "no-referrer",
+		"Strict-Transport-Security": "max-age=31536000",
+	} {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package http
 
-import "github.com/randomtoy/taas-go/internal/domain"
+import (
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+)
 
 // TarotResponse is the JSON shape returned by GET /v1/tarot.
 type TarotResponse struct {
+	ReadingID      string             `json:"reading_id,omitempty"`
 	Spread         string             `json:"spread"`
 	Deck           string             `json:"deck"`
 	Cards          []CardResponse     `json:"cards"`
@@ -12,26 +17,163 @@ type TarotResponse struct {
 }
 
 type CardResponse struct {
-	ID          string          `json:"id"`
-	Name        string          `json:"name"`
-	Position    int             `json:"position"`
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Position    int                `json:"position"`
 	Orientation domain.Orientation `json:"orientation"`
-	Keywords    []string        `json:"keywords"`
-	Short       string          `json:"short"`
+	Keywords    []string           `json:"keywords"`
+	Short       string             `json:"short"`
 }
 
 type InterpretationResp struct {
-	Style      string `json:"style"`
-	Text       string `json:"text"`
-	Disclaimer string `json:"disclaimer"`
+	Style      string                   `json:"style"`
+	Text       string                   `json:"text"`
+	Summary    string                   `json:"summary,omitempty"`
+	Theme      string                   `json:"theme,omitempty"`
+	Cards      []CardInterpretationResp `json:"cards,omitempty"`
+	Questions  []string                 `json:"questions,omitempty"`
+	Disclaimer string                   `json:"disclaimer"`
+}
+
+// CardInterpretationResp is the note shown under the card at Position.
+type CardInterpretationResp struct {
+	Position int    `json:"position"`
+	CardID   string `json:"card_id"`
+	Text     string `json:"text"`
 }
 
 type MetaResp struct {
-	Model     string `json:"model"`
-	RequestID string `json:"request_id"`
-	LatencyMS int64  `json:"latency_ms"`
+	Model         string     `json:"model"`
+	PromptVersion string     `json:"prompt_version,omitempty"`
+	RequestID     string     `json:"request_id"`
+	LatencyMS     int64      `json:"latency_ms"`
+	Safety        SafetyResp `json:"safety"`
+	Route         *RouteResp `json:"route,omitempty"`
+}
+
+// SafetyResp reports what the safety guardrails decided for this reading.
+type SafetyResp struct {
+	Action     string   `json:"action"`
+	Categories []string `json:"categories,omitempty"`
+	Flags      []string `json:"flags,omitempty"`
+}
+
+// RouteResp reports which routing target answered, when routing is
+// configured.
+type RouteResp struct {
+	Target     string   `json:"target"`
+	Reason     string   `json:"reason"`
+	FailedOver []string `json:"failed_over,omitempty"`
+}
+
+// ReadingRequest holds the parameters of a reading: the query of
+// GET /v1/tarot or the body of POST /v1/readings.
+type ReadingRequest struct {
+	Question   string `json:"q"`
+	N          *int   `json:"n"` // nil selects the default of 3
+	Deck       string `json:"deck"`
+	Spread     string `json:"spread"`
+	Lang       string `json:"lang"`
+	Style      string `json:"style"`
+	Length     string `json:"length"`
+	Seed       *int64 `json:"seed"`
+	WebhookURL string `json:"webhook_url"` // async only
+}
+
+// BatchItem is one reading in a batch; ID is echoed back for correlation.
+type BatchItem struct {
+	ID string `json:"id,omitempty"`
+	ReadingRequest
+}
+
+// BatchItemResult is the outcome of the item at Index: Result on success,
+// Error otherwise, with the status a single request would have returned.
+type BatchItemResult struct {
+	Index  int            `json:"index"`
+	ID     string         `json:"id,omitempty"`
+	Status int            `json:"status"`
+	Result *TarotResponse `json:"result,omitempty"`
+	Error  *Problem       `json:"error,omitempty"`
+}
+
+// BatchResponse is returned when results are not streamed.
+type BatchResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// JobAccepted is returned with 202 when a reading is queued.
+type JobAccepted struct {
+	JobID     string `json:"job_id"`
+	Status    string `json:"status"`
+	StatusURL string `json:"status_url"`
+}
+
+// FollowUpRequest is the body of POST /v1/readings/{id}/followups.
+type FollowUpRequest struct {
+	Question string `json:"question"`
+}
+
+// FollowUpResponse answers one follow-up question.
+type FollowUpResponse struct {
+	ReadingID      string             `json:"reading_id"`
+	Turn           int                `json:"turn"` // 1 for the first follow-up
+	Question       string             `json:"question"`
+	Interpretation InterpretationResp `json:"interpretation"`
+	Meta           MetaResp           `json:"meta"`
+}
+
+// ClarifyRequest is the body of POST /v1/readings/{id}/clarifiers.
+type ClarifyRequest struct {
+	Position int    `json:"position"`
+	Count    int    `json:"count"`
+	Question string `json:"question"`
+}
+
+// ClarifyResponse holds clarifiers drawn for one position and their
+// interpretation.
+type ClarifyResponse struct {
+	ReadingID      string              `json:"reading_id"`
+	Turn           int                 `json:"turn"`
+	ForPosition    int                 `json:"for_position"`
+	Clarifiers     []ClarifierResponse `json:"clarifiers"`
+	Interpretation InterpretationResp  `json:"interpretation"`
+	Meta           MetaResp            `json:"meta"`
+}
+
+// ClarifierResponse is a clarifier card attached to spread position
+// ForPosition.
+type ClarifierResponse struct {
+	CardResponse
+	ForPosition int `json:"for_position"`
+}
+
+// ReadingResponse is a stored reading with its conversation thread,
+// returned by GET /v1/readings/{id}.
+type ReadingResponse struct {
+	ID         string              `json:"id"`
+	Spread     string              `json:"spread"`
+	Deck       string              `json:"deck"`
+	Lang       string              `json:"lang"`
+	Style      string              `json:"style"`
+	Cards      []CardResponse      `json:"cards"`
+	Clarifiers []ClarifierResponse `json:"clarifiers,omitempty"`
+	Thread     []TurnResponse      `json:"thread"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+type TurnResponse struct {
+	Question       string              `json:"question"`
+	Clarifiers     []ClarifierResponse `json:"clarifiers,omitempty"`
+	Interpretation InterpretationResp  `json:"interpretation"`
+	Model          string              `json:"model"`
+	CreatedAt      time.Time           `json:"created_at"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// StyleResponse describes one persona returned by GET /v1/styles.
+type StyleResponse struct {
+	Style       string `json:"style"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
 }
@@ -1,15 +1,15 @@
 package http
 
 import (
-	"errors"
-	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/randomtoy/taas-go/internal/app"
 	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
 )
 
 type Handler struct {
@@ -23,6 +23,10 @@ func NewHandler(svc *app.TarotService) *Handler {
 func (h *Handler) Register(e *echo.Echo) {
 	e.GET("/healthz", h.Healthz)
 	e.GET("/v1/tarot", h.ReadTarot)
+	e.GET("/v1/styles", h.ListStyles)
+	e.GET("/v1/readings/:id", h.GetReading)
+	e.POST("/v1/readings/:id/followups", h.FollowUp)
+	e.POST("/v1/readings/:id/clarifiers", h.Clarify)
 }
 
 func (h *Handler) Healthz(c echo.Context) error {
@@ -30,56 +34,208 @@ func (h *Handler) Healthz(c echo.Context) error {
 }
 
 func (h *Handler) ReadTarot(c echo.Context) error {
-	q := c.QueryParam("q")
-	if len(q) > 500 {
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "q must be at most 500 characters"})
+	params := ReadingRequest{
+		Question: c.QueryParam("q"),
+		Deck:     c.QueryParam("deck"),
+		Spread:   c.QueryParam("spread"),
+		Lang:     c.QueryParam("lang"),
+		Style:    c.QueryParam("style"),
+		Length:   c.QueryParam("length"),
 	}
-
-	n := 3
 	if raw := c.QueryParam("n"); raw != "" {
 		parsed, err := strconv.Atoi(raw)
-		if err != nil || parsed < 1 || parsed > 10 {
-			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "n must be an integer between 1 and 10"})
+		if err != nil {
+			return invalid(c, []FieldError{errInvalidN})
 		}
-		n = parsed
+		params.N = &parsed
 	}
-
-	deckID := c.QueryParam("deck")
-	if deckID == "" {
-		deckID = "major_arcana"
+	if raw := c.QueryParam("seed"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return invalid(c, []FieldError{errInvalidSeed})
+		}
+		params.Seed = &parsed
 	}
 
-	spread := c.QueryParam("spread")
-	if spread == "" {
-		spread = "generic"
+	resp, err := h.svc.ReadSpread(c.Request().Context(), params.toApp(c.Request().Header.Get(headerAPIKey)))
+	if err != nil {
+		return mapError(c, err)
 	}
 
-	lang := c.QueryParam("lang")
-	if lang == "" {
-		lang = "en"
+	requestID, _ := c.Get("request_id").(string)
+
+	return c.JSON(http.StatusOK, toResponse(resp, requestID))
+}
+
+var (
+	errInvalidN    = FieldError{Field: "n", Code: codeInvalidN, Detail: "n must be of type integer"}
+	errInvalidSeed = FieldError{Field: "seed", Code: codeInvalidParameter, Detail: "seed must be of type integer"}
+)
+
+// toApp applies the defaults of a reading's parameters. Their limits are
+// enforced by OpenAPIValidator and the domain.
+func (r ReadingRequest) toApp(apiKey string) app.ReadSpreadRequest {
+	n := 3
+	if r.N != nil {
+		n = *r.N
 	}
 
-	req := app.ReadSpreadRequest{
-		Question:   q,
+	return app.ReadSpreadRequest{
+		Question:   r.Question,
 		NumCards:   n,
-		DeckID:     deckID,
-		SpreadType: spread,
-		Lang:       lang,
+		DeckID:     orDefault(r.Deck, "major_arcana"),
+		SpreadType: orDefault(r.Spread, "generic"),
+		Lang:       orDefault(r.Lang, "en"),
+		Style:      r.Style,
+		Length:     r.Length,
+		Seed:       r.Seed,
+		APIKey:     apiKey,
+	}
+}
+
+func orDefault(v, fallback string) string {
+	if v == "" {
+		return fallback
 	}
+	return v
+}
 
-	resp, err := h.svc.ReadSpread(c.Request().Context(), req)
+// ListStyles returns the selectable interpretation personas.
+func (h *Handler) ListStyles(c echo.Context) error {
+	personas := domain.Personas()
+	styles := make([]StyleResponse, len(personas))
+	for i, p := range personas {
+		styles[i] = StyleResponse{Style: string(p.Style), Description: p.Description, Default: p.Style == domain.DefaultStyle}
+	}
+	return c.JSON(http.StatusOK, styles)
+}
+
+// GetReading returns a stored reading with its conversation thread.
+func (h *Handler) GetReading(c echo.Context) error {
+	r, err := h.svc.GetReading(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return mapError(c, err)
+	}
+	return c.JSON(http.StatusOK, toReadingResponse(r))
+}
+
+// FollowUp asks another question about the cards of an existing reading.
+func (h *Handler) FollowUp(c echo.Context) error {
+	var body FollowUpRequest
+	if err := c.Bind(&body); err != nil {
+		return problem(c, codeInvalidRequestBody, "the body must be a JSON object")
+	}
+
+	resp, err := h.svc.FollowUp(c.Request().Context(), app.FollowUpRequest{
+		ReadingID: c.Param("id"),
+		Question:  body.Question,
+		APIKey:    c.Request().Header.Get(headerAPIKey),
+	})
 	if err != nil {
 		return mapError(c, err)
 	}
 
 	requestID, _ := c.Get("request_id").(string)
 
-	return c.JSON(http.StatusOK, toResponse(resp, requestID))
+	return c.JSON(http.StatusOK, FollowUpResponse{
+		ReadingID:      resp.Reading.ID,
+		Turn:           len(resp.Reading.Thread) - 1,
+		Question:       body.Question,
+		Interpretation: toInterpretation(resp.Interpretation, resp.Reading.Cards),
+		Meta:           toMeta(resp.Interpretation, resp.Model, resp.PromptVersion, requestID, resp.LatencyMS),
+	})
+}
+
+// Clarify draws clarifier cards for one position of an existing reading.
+func (h *Handler) Clarify(c echo.Context) error {
+	body := ClarifyRequest{Count: 1}
+	if err := c.Bind(&body); err != nil {
+		return problem(c, codeInvalidRequestBody, "the body must be a JSON object")
+	}
+
+	resp, err := h.svc.Clarify(c.Request().Context(), app.ClarifyRequest{
+		ReadingID: c.Param("id"),
+		Position:  body.Position,
+		Count:     body.Count,
+		Question:  body.Question,
+		APIKey:    c.Request().Header.Get(headerAPIKey),
+	})
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	requestID, _ := c.Get("request_id").(string)
+
+	drawn := clarifierCards(resp.Clarifiers)
+	return c.JSON(http.StatusOK, ClarifyResponse{
+		ReadingID:      resp.Reading.ID,
+		Turn:           len(resp.Reading.Thread) - 1,
+		ForPosition:    body.Position,
+		Clarifiers:     toClarifiers(resp.Clarifiers),
+		Interpretation: toInterpretation(resp.Interpretation, drawn),
+		Meta:           toMeta(resp.Interpretation, resp.Model, resp.PromptVersion, requestID, resp.LatencyMS),
+	})
 }
 
 func toResponse(r app.ReadSpreadResponse, requestID string) TarotResponse {
-	cards := make([]CardResponse, len(r.Cards))
-	for i, dc := range r.Cards {
+	return TarotResponse{
+		ReadingID:      r.ReadingID,
+		Spread:         string(r.SpreadType),
+		Deck:           r.DeckID,
+		Cards:          toCards(r.Cards),
+		Interpretation: toInterpretation(r.Interpretation, r.Cards),
+		Meta:           toMeta(r.Interpretation, r.Model, r.PromptVersion, requestID, r.LatencyMS),
+	}
+}
+
+func toReadingResponse(r ports.Reading) ReadingResponse {
+	table := append(slices.Clip(r.Cards), clarifierCards(r.Clarifiers)...)
+	thread := make([]TurnResponse, len(r.Thread))
+	for i, t := range r.Thread {
+		thread[i] = TurnResponse{
+			Question:       t.Question,
+			Clarifiers:     toClarifiers(t.Clarifiers),
+			Interpretation: toInterpretation(t.Interpretation, table),
+			Model:          t.Model,
+			CreatedAt:      t.CreatedAt,
+		}
+	}
+	return ReadingResponse{
+		ID:         r.ID,
+		Spread:     string(r.Spread),
+		Deck:       r.DeckID,
+		Lang:       r.Lang,
+		Style:      r.Style,
+		Cards:      toCards(r.Cards),
+		Clarifiers: toClarifiers(r.Clarifiers),
+		Thread:     thread,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+func clarifierCards(cs []domain.Clarifier) []domain.DrawnCard {
+	out := make([]domain.DrawnCard, len(cs))
+	for i, c := range cs {
+		out[i] = c.DrawnCard
+	}
+	return out
+}
+
+func toClarifiers(cs []domain.Clarifier) []ClarifierResponse {
+	if len(cs) == 0 {
+		return nil
+	}
+	cards := toCards(clarifierCards(cs))
+	out := make([]ClarifierResponse, len(cs))
+	for i, c := range cs {
+		out[i] = ClarifierResponse{CardResponse: cards[i], ForPosition: c.ForPosition}
+	}
+	return out
+}
+
+func toCards(drawn []domain.DrawnCard) []CardResponse {
+	cards := make([]CardResponse, len(drawn))
+	for i, dc := range drawn {
 		cards[i] = CardResponse{
 			ID:          dc.ID,
 			Name:        dc.Name,
@@ -89,36 +245,56 @@ func toResponse(r app.ReadSpreadResponse, requestID string) TarotResponse {
 			Short:       dc.Short,
 		}
 	}
-	return TarotResponse{
-		Spread: string(r.SpreadType),
-		Deck:   r.DeckID,
-		Cards:  cards,
-		Interpretation: InterpretationResp{
-			Style:      r.Interpretation.Style,
-			Text:       r.Interpretation.Text,
-			Disclaimer: r.Interpretation.Disclaimer,
-		},
-		Meta: MetaResp{
-			Model:     r.Model,
-			RequestID: requestID,
-			LatencyMS: r.LatencyMS,
+	return cards
+}
+
+func toInterpretation(out ports.InterpretOutput, drawn []domain.DrawnCard) InterpretationResp {
+	return InterpretationResp{
+		Style:      out.Style,
+		Text:       out.Text,
+		Summary:    out.Summary,
+		Theme:      out.Theme,
+		Cards:      toCardInterpretations(out.Cards, drawn),
+		Questions:  out.Questions,
+		Disclaimer: out.Disclaimer,
+	}
+}
+
+func toMeta(out ports.InterpretOutput, model, promptVersion, requestID string, latencyMS int64) MetaResp {
+	var route *RouteResp
+	if out.Route.Target != "" {
+		route = &RouteResp{Target: out.Route.Target, Reason: out.Route.Reason, FailedOver: out.Route.FailedOver}
+	}
+	return MetaResp{
+		Model:         model,
+		PromptVersion: promptVersion,
+		RequestID:     requestID,
+		LatencyMS:     latencyMS,
+		Safety: SafetyResp{
+			Action:     safetyAction(out.Safety.Action),
+			Categories: out.Safety.Categories,
+			Flags:      out.Safety.Flags,
 		},
+		Route: route,
 	}
 }
 
-func mapError(c echo.Context, err error) error {
-	requestID, _ := c.Get("request_id").(string)
+// safetyAction reports "none" when no guardrails ran.
+func safetyAction(action string) string {
+	if action == "" {
+		return "none"
+	}
+	return action
+}
 
-	switch {
-	case errors.Is(err, domain.ErrDeckNotFound):
-		return c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
-	case errors.Is(err, domain.ErrInvalidN), errors.Is(err, domain.ErrNExceedsDeck):
-		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-	case errors.Is(err, domain.ErrUpstreamLLM), errors.Is(err, domain.ErrInvalidLLMJSON):
-		slog.Error("upstream LLM failure", "request_id", requestID, "error", err)
-		return c.JSON(http.StatusBadGateway, ErrorResponse{Error: "upstream LLM failure"})
-	default:
-		slog.Error("internal error", "request_id", requestID, "error", err)
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "internal error"})
+func toCardInterpretations(notes []ports.CardInterpretation, drawn []domain.DrawnCard) []CardInterpretationResp {
+	ids := make(map[int]string, len(drawn))
+	for _, dc := range drawn {
+		ids[dc.Position] = dc.ID
+	}
+	out := make([]CardInterpretationResp, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, CardInterpretationResp{Position: n.Position, CardID: ids[n.Position], Text: n.Text})
 	}
+	return out
 }
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/internal/health"
+)
+
+// HealthHandler serves the Kubernetes probes. Liveness only says the
+// process is serving; readiness reports the latest dependency checks,
+// which run in the background rather than on every probe.
+type HealthHandler struct {
+	monitor *health.Monitor
+}
+
+func NewHealthHandler(monitor *health.Monitor) *HealthHandler {
+	return &HealthHandler{monitor: monitor}
+}
+
+func (h *HealthHandler) Register(e *echo.Echo) {
+	e.GET("/livez", h.Livez)
+	e.GET("/readyz", h.Readyz)
+}
+
+func (h *HealthHandler) Livez(c echo.Context) error {
+	return c.String(http.StatusOK, "OK")
+}
+
+// Readyz returns 200 when every required dependency's latest check passed
+// and 503 otherwise, with per-component status either way. A failing
+// optional dependency only marks the report degraded.
+func (h *HealthHandler) Readyz(c echo.Context) error {
+	report := h.monitor.Report()
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLen     = 255
+)
+
+// IdempotencyStore remembers the response to each Idempotency-Key for ttl
+// so that retries are answered without repeating the work. Keys are scoped
+// by X-Api-Key. Contents are kept in memory and lost on restart.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotencyEntry
+	expiry  []expiring // completed keys; ttl is fixed, so in order of expiry
+	now     func() time.Time
+}
+
+type expiring struct {
+	key string
+	at  time.Time
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	done        bool // false while the first request is in flight
+	expires     time.Time
+
+	status int
+	header http.Header
+	body   []byte
+}
+
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// begin claims key for a request with fingerprint. It returns the stored
+// entry when the key is already taken, or nil when the caller now owns it
+// and must call complete or release.
+func (s *IdempotencyStore) begin(key, fingerprint string) *idempotencyEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+	if e, ok := s.entries[key]; ok && !(e.done && now.After(e.expires)) {
+		snapshot := *e
+		return &snapshot
+	}
+	s.entries[key] = &idempotencyEntry{fingerprint: fingerprint}
+	return nil
+}
+
+func (s *IdempotencyStore) complete(key string, status int, header http.Header, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	e.done = true
+	e.expires = s.now().Add(s.ttl)
+	s.expiry = append(s.expiry, expiring{key: key, at: e.expires})
+	e.status = status
+	e.header = header
+	e.body = body
+}
+
+// expire drops the entries that expired by now from the front of the
+// expiry queue, so each request only pays for the entries it removes. A
+// queued key that was released and completed again since is left to its
+// newer queue item.
+func (s *IdempotencyStore) expire(now time.Time) {
+	n := 0
+	for ; n < len(s.expiry) && now.After(s.expiry[n].at); n++ {
+		x := s.expiry[n]
+		if e, ok := s.entries[x.key]; ok && e.done && e.expires.Equal(x.at) {
+			delete(s.entries, x.key)
+		}
+		s.expiry[n] = expiring{}
+	}
+	s.expiry = s.expiry[n:]
+}
+
+// release forgets key so that a retry runs the request again.
+func (s *IdempotencyStore) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// IdempotencyMiddleware makes POST requests that carry an Idempotency-Key
+// safe to retry. The first response for a key is stored and replayed for
+// retries with the same method, path, query and body; reusing the key for
+// a different request returns 422, and a retry while the first request is
+// still running returns 409. Server errors (5xx) and retryable problems,
+// such as a 429 for an exhausted budget, are not stored, so the request
+// can be retried for real.
+func IdempotencyMiddleware(store *IdempotencyStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(headerIdempotencyKey)
+			if key == "" || req.Method != http.MethodPost {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				return problem(c, codeInvalidIdempotencyKey, "Idempotency-Key must be at most 255 characters")
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if ok, p := bodyTooLarge(c, err); ok {
+				return p
+			}
+			if err != nil {
+				return problem(c, codeInvalidRequestBody, "the body could not be read")
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			scoped := req.Header.Get(headerAPIKey) + "\x00" + key
+			fp := fingerprint(req, body)
+			if prev := store.begin(scoped, fp); prev != nil {
+				switch {
+				case prev.fingerprint != fp:
+					return problem(c, codeIdempotencyKeyReused, "Idempotency-Key was already used for a different request")
+				case !prev.done:
+					return problem(c, codeIdempotencyInProgress, "a request with this Idempotency-Key is still in progress")
+				}
+				return replay(c, prev)
+			}
+
+			rec := &recordingWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			err = next(c)
+			c.Response().Writer = rec.ResponseWriter
+
+			status := c.Response().Status
+			retryable, _ := c.Get(ctxRetryable).(bool)
+			if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError || retryable {
+				store.release(scoped)
+				return err
+			}
+			header := c.Response().Header().Clone()
+			header.Del(headerRequestID)
+			store.complete(scoped, status, header, rec.body.Bytes())
+			return nil
+		}
+	}
+}
+
+func replay(c echo.Context, e *idempotencyEntry) error {
+	res := c.Response()
+	for k, v := range e.header {
+		res.Header()[k] = v
+	}
+	res.Header().Set(headerIdempotentReplayed, "true")
+	res.WriteHeader(e.status)
+	_, err := res.Write(e.body)
+	return err
+}
+
+// fingerprint identifies what a request asks for, so a key cannot be
+// reused for something else.
+func fingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, req.Method+" "+req.URL.Path+"?"+req.URL.RawQuery+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter copies everything written to the client.
+type recordingWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newIdempotentEcho(t *testing.T, handler echo.HandlerFunc) *echo.Echo {
+	t.Helper()
+	e := echo.New()
+	e.Use(IdempotencyMiddleware(NewIdempotencyStore(time.Hour)))
+	e.POST("/v1/readings", handler)
+	return e
+}
+
+func post(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/readings", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(headerIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	var calls atomic.Int32
+	e := newIdempotentEcho(t, func(c echo.Context) error {
+		n := calls.Add(1)
+		return c.JSON(http.StatusOK, map[string]int32{"call": n})
+	})
+
+	first := post(e, "k1", `{"q":"a"}`)
+	retry := post(e, "k1", `{"q":"a"}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %q, want %d %q", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get(headerIdempotentReplayed) != "true" {
+		t.Error("replay is not marked")
+	}
+	if retry.Header().Get(echo.HeaderContentType) != first.Header().Get(echo.HeaderContentType) {
+		t.Error("replay lost the content type")
+	}
+
+	post(e, "", `{"q":"a"}`)
+	if calls.Load() != 2 {
+		t.Error("request without a key should not be deduplicated")
+	}
+}
+
+func TestIdempotency_DifferentPayload(t *testing.T) {
+	e := newIdempotentEcho(t, func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+
+	post(e, "k1", `{"q":"a"}`)
+	if rec := post(e, "k1", `{"q":"b"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", rec.Code)
+	}
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e := newIdempotentEcho(t, func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusOK)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(e, "k1", `{}`) }()
+	<-started
+
+	if rec := post(e, "k1", `{}`); rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", rec.Code)
+	}
+	close(release)
+	if rec := <-done; rec.Code != http.StatusOK {
+		t.Errorf("first request status = %d", rec.Code)
+	}
+}
+
+func TestIdempotency_ServerErrorsAreRetried(t *testing.T) {
+	var calls atomic.Int32
+	e := newIdempotentEcho(t, func(c echo.Context) error {
+		if calls.Add(1) == 1 {
+			return problem(c, codeUpstreamLLM, "")
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	post(e, "k1", `{}`)
+	if rec := post(e, "k1", `{}`); rec.Code != http.StatusOK {
+		t.Errorf("retry status = %d, want 200", rec.Code)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotency_RetryableProblemsAreRetried(t *testing.T) {
+	var calls atomic.Int32
+	e := newIdempotentEcho(t, func(c echo.Context) error {
+		if calls.Add(1) == 1 {
+			return problem(c, codeBudgetExceeded, "budget used up")
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	if rec := post(e, "k1", `{}`); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("first status = %d, want 429", rec.Code)
+	}
+	if rec := post(e, "k1", `{}`); rec.Code != http.StatusOK || rec.Header().Get(headerIdempotentReplayed) != "" {
+		t.Errorf("retry status = %d, replayed %q; want a fresh 200", rec.Code, rec.Header().Get(headerIdempotentReplayed))
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler ran %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotencyStore_Expiry(t *testing.T) {
+	s := NewIdempotencyStore(time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	if s.begin("k", "fp") != nil {
+		t.Fatal("new key reported as taken")
+	}
+	s.complete("k", http.StatusOK, nil, nil)
+	if s.begin("k", "fp") == nil {
+		t.Fatal("completed key not found")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if s.begin("k", "fp") != nil {
+		t.Error("expired key still taken")
+	}
+}
+
+func TestIdempotencyStore_ExpiresInOrder(t *testing.T) {
+	s := NewIdempotencyStore(time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	for _, k := range []string{"a", "b"} {
+		s.begin(k, "fp")
+		s.complete(k, http.StatusOK, nil, nil)
+		now = now.Add(30 * time.Second)
+	}
+	// Released and completed again: the first queue item for "a" is stale.
+	s.release("a")
+	s.begin("a", "fp")
+	s.complete("a", http.StatusOK, nil, nil)
+
+	now = now.Add(35 * time.Second) // "b" and the stale "a" item expired
+	s.begin("c", "fp")
+	if _, ok := s.entries["b"]; ok {
+		t.Error("expired key b kept")
+	}
+	if _, ok := s.entries["a"]; !ok {
+		t.Error("key a dropped by its stale expiry")
+	}
+	if len(s.expiry) != 1 || s.expiry[0].key != "a" {
+		t.Errorf("expiry queue = %+v", s.expiry)
+	}
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/jobs"
+)
+
+// JobsHandler serves POST /v1/readings, which can run a reading
+// asynchronously on the job queue, and job polling.
+type JobsHandler struct {
+	svc   *app.TarotService
+	queue *jobs.Queue
+}
+
+func NewJobsHandler(svc *app.TarotService, queue *jobs.Queue) *JobsHandler {
+	return &JobsHandler{svc: svc, queue: queue}
+}
+
+func (h *JobsHandler) Register(e *echo.Echo) {
+	e.POST("/v1/readings", h.CreateReading)
+	e.GET("/v1/jobs/:id", h.GetJob)
+}
+
+// CreateReading generates a reading from a JSON body. With ?async=true it
+// is queued and 202 is returned with a job to poll; otherwise it behaves
+// like GET /v1/tarot.
+func (h *JobsHandler) CreateReading(c echo.Context) error {
+	var body ReadingRequest
+	if err := c.Bind(&body); err != nil {
+		return problem(c, codeInvalidRequestBody, "the body must be a JSON object")
+	}
+	req := body.toApp(c.Request().Header.Get(headerAPIKey))
+
+	async := false
+	if raw := c.QueryParam("async"); raw != "" {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid(c, []FieldError{{Field: "async", Code: codeInvalidParameter, Detail: "async must be true or false"}})
+		}
+		async = b
+	}
+
+	requestID, _ := c.Get("request_id").(string)
+
+	if !async {
+		if body.WebhookURL != "" {
+			return invalid(c, []FieldError{{Field: "webhook_url", Code: codeInvalidWebhookURL, Detail: "webhook_url requires async=true"}})
+		}
+		resp, err := h.svc.ReadSpread(c.Request().Context(), req)
+		if err != nil {
+			return mapError(c, err)
+		}
+		return c.JSON(http.StatusOK, toResponse(resp, requestID))
+	}
+
+	if body.WebhookURL != "" {
+		if !h.queue.WebhooksEnabled() {
+			return problem(c, codeWebhooksDisabled, "webhooks are not enabled on this server")
+		}
+		if err := h.queue.CheckWebhookURL(body.WebhookURL); err != nil {
+			return invalid(c, []FieldError{{Field: "webhook_url", Code: codeInvalidWebhookURL, Detail: err.Error()}})
+		}
+	}
+
+	job, err := h.queue.Submit(func(ctx context.Context) (any, error) {
+		resp, err := h.svc.ReadSpread(ctx, req)
+		if err != nil {
+			p := problemFor(err, requestID)
+			return nil, &jobs.Failure{Status: p.Status, Code: p.Code, Message: p.Detail, Retryable: p.Retryable}
+		}
+		return toResponse(resp, requestID), nil
+	}, body.WebhookURL)
+	if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, jobs.ErrQueueClosed) {
+		c.Response().Header().Set("Retry-After", "5")
+		return problem(c, codeQueueUnavailable, err.Error())
+	}
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	statusURL := "/v1/jobs/" + job.ID
+	c.Response().Header().Set(echo.HeaderLocation, statusURL)
+	return c.JSON(http.StatusAccepted, JobAccepted{JobID: job.ID, Status: string(job.Status), StatusURL: statusURL})
+}
+
+// GetJob reports a job's status and, once it has finished, its result or
+// error.
+func (h *JobsHandler) GetJob(c echo.Context) error {
+	job, err := h.queue.Get(c.Param("id"))
+	if errors.Is(err, jobs.ErrNotFound) {
+		return problem(c, codeJobNotFound, err.Error())
+	}
+	if err != nil {
+		return mapError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
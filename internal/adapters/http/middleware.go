@@ -9,7 +9,10 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-const headerRequestID = "X-Request-Id"
+const (
+	headerRequestID = "X-Request-Id"
+	headerAPIKey    = "X-Api-Key" // client-chosen and unauthenticated; only attributes spend
+)
 
 // RequestIDMiddleware ensures every request has a unique X-Request-Id.
 func RequestIDMiddleware() echo.MiddlewareFunc {
@@ -0,0 +1,270 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+)
+
+const (
+	mimeProblemJSON   = "application/problem+json"
+	problemTypePrefix = "urn:taas:problem:"
+
+	// ctxRetryable is set on the context when the response is a retryable
+	// problem, so middleware can tell without parsing the body.
+	ctxRetryable = "problem_retryable"
+)
+
+// Stable error codes. Clients match on these, never on messages, so a code
+// must not change meaning once published; api/openapi.yaml lists them all.
+const (
+	codeBadRequest            = "bad_request"
+	codeInvalidRequestBody    = "invalid_request_body"
+	codeValidationFailed      = "validation_failed"
+	codeInvalidParameter      = "invalid_parameter"
+	codeInvalidField          = "invalid_field"
+	codeInvalidN              = "invalid_n"
+	codeNExceedsDeck          = "n_exceeds_deck"
+	codeQuestionRequired      = "question_required"
+	codeQuestionTooLong       = "question_too_long"
+	codeUnknownStyle          = "unknown_style"
+	codeInvalidLength         = "invalid_length"
+	codeInvalidPosition       = "invalid_position"
+	codeInvalidClarifierCount = "invalid_clarifier_count"
+	codeInvalidWebhookURL     = "invalid_webhook_url"
+	codeWebhooksDisabled      = "webhooks_disabled"
+	codeBatchEmpty            = "batch_empty"
+	codeBatchTooLarge         = "batch_too_large"
+	codeInvalidIdempotencyKey = "invalid_idempotency_key"
+	codeBodyTooLarge          = "body_too_large"
+	codeUnauthorized          = "unauthorized"
+	codeNotFound              = "not_found"
+	codeDeckNotFound          = "deck_not_found"
+	codeReadingNotFound       = "reading_not_found"
+	codeJobNotFound           = "job_not_found"
+	codeMethodNotAllowed      = "method_not_allowed"
+	codeFollowUpLimit         = "followup_limit_reached"
+	codeNoCardsLeft           = "no_cards_left"
+	codeReadingChanged        = "reading_changed"
+	codeIdempotencyInProgress = "idempotency_key_in_progress"
+	codeIdempotencyKeyReused  = "idempotency_key_reused"
+	codeRateLimited           = "rate_limited"
+	codeBudgetExceeded        = "budget_exceeded"
+	codeInvalidConfig         = "invalid_config"
+	codeInternal              = "internal_error"
+	codeUpstreamLLM           = "upstream_llm_failure"
+	codeQueueUnavailable      = "queue_unavailable"
+)
+
+// problemKind is what every problem with a given code has in common.
+type problemKind struct {
+	status    int
+	title     string
+	retryable bool
+}
+
+var problemKinds = map[string]problemKind{
+	codeBadRequest:            {http.StatusBadRequest, "Bad request", false},
+	codeInvalidRequestBody:    {http.StatusBadRequest, "Invalid request body", false},
+	codeValidationFailed:      {http.StatusBadRequest, "Validation failed", false},
+	codeInvalidParameter:      {http.StatusBadRequest, "Invalid parameter", false},
+	codeInvalidField:          {http.StatusBadRequest, "Invalid field", false},
+	codeInvalidN:              {http.StatusBadRequest, "Invalid number of cards", false},
+	codeNExceedsDeck:          {http.StatusBadRequest, "Not enough cards in deck", false},
+	codeQuestionRequired:      {http.StatusBadRequest, "Question required", false},
+	codeQuestionTooLong:       {http.StatusBadRequest, "Question too long", false},
+	codeUnknownStyle:          {http.StatusBadRequest, "Unknown style", false},
+	codeInvalidLength:         {http.StatusBadRequest, "Invalid length", false},
+	codeInvalidPosition:       {http.StatusBadRequest, "Invalid position", false},
+	codeInvalidClarifierCount: {http.StatusBadRequest, "Invalid clarifier count", false},
+	codeInvalidWebhookURL:     {http.StatusBadRequest, "Invalid webhook URL", false},
+	codeWebhooksDisabled:      {http.StatusBadRequest, "Webhooks disabled", false},
+	codeBatchEmpty:            {http.StatusBadRequest, "Empty batch", false},
+	codeBatchTooLarge:         {http.StatusBadRequest, "Batch too large", false},
+	codeInvalidIdempotencyKey: {http.StatusBadRequest, "Invalid idempotency key", false},
+	codeBodyTooLarge:          {http.StatusRequestEntityTooLarge, "Body too large", false},
+	codeUnauthorized:          {http.StatusUnauthorized, "Unauthorized", false},
+	codeNotFound:              {http.StatusNotFound, "Not found", false},
+	codeDeckNotFound:          {http.StatusNotFound, "Deck not found", false},
+	codeReadingNotFound:       {http.StatusNotFound, "Reading not found", false},
+	codeJobNotFound:           {http.StatusNotFound, "Job not found", false},
+	codeMethodNotAllowed:      {http.StatusMethodNotAllowed, "Method not allowed", false},
+	codeFollowUpLimit:         {http.StatusConflict, "Follow-up limit reached", false},
+	codeNoCardsLeft:           {http.StatusConflict, "No cards left", false},
+	codeReadingChanged:        {http.StatusConflict, "Reading changed", true},
+	codeIdempotencyInProgress: {http.StatusConflict, "Request in progress", true},
+	codeIdempotencyKeyReused:  {http.StatusUnprocessableEntity, "Idempotency key reused", false},
+	codeRateLimited:           {http.StatusTooManyRequests, "Rate limited", true},
+	codeBudgetExceeded:        {http.StatusTooManyRequests, "Budget exceeded", true},
+	codeInvalidConfig:         {http.StatusUnprocessableEntity, "Invalid configuration", false},
+	codeInternal:              {http.StatusInternalServerError, "Internal error", false},
+	codeUpstreamLLM:           {http.StatusBadGateway, "Upstream LLM failure", true},
+	codeQueueUnavailable:      {http.StatusServiceUnavailable, "Queue unavailable", true},
+}
+
+// Problem is an RFC 7807 problem details object, extended with a stable
+// code, the request ID and whether retrying the same request may succeed.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Retryable bool         `json:"retryable"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError reports one invalid request field.
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+func newProblem(code, detail, requestID string, errs ...FieldError) Problem {
+	kind, ok := problemKinds[code]
+	if !ok {
+		code, kind = codeInternal, problemKinds[codeInternal]
+	}
+	return Problem{
+		Type:      problemTypePrefix + code,
+		Title:     kind.title,
+		Status:    kind.status,
+		Detail:    detail,
+		Code:      code,
+		RequestID: requestID,
+		Retryable: kind.retryable,
+		Errors:    errs,
+	}
+}
+
+// problem writes an application/problem+json response.
+func problem(c echo.Context, code, detail string, errs ...FieldError) error {
+	requestID, _ := c.Get("request_id").(string)
+	return writeProblem(c, newProblem(code, detail, requestID, errs...))
+}
+
+func writeProblem(c echo.Context, p Problem) error {
+	p.Instance = c.Request().URL.Path
+	c.Set(ctxRetryable, p.Retryable)
+	h := c.Response().Header()
+	if p.Code == codeRateLimited && h.Get(headerRetryAfter) == "" {
+		// Too many LLM calls in flight: a slot usually frees up quickly.
+		h.Set(headerRetryAfter, "1")
+	}
+	h.Set(echo.HeaderContentType, mimeProblemJSON)
+	return c.JSON(p.Status, p)
+}
+
+// invalid reports request validation errors. A single error keeps its own
+// code; several are grouped under validation_failed.
+func invalid(c echo.Context, errs []FieldError) error {
+	requestID, _ := c.Get("request_id").(string)
+	return writeProblem(c, validationProblem(errs, requestID))
+}
+
+func validationProblem(errs []FieldError, requestID string) Problem {
+	code := codeValidationFailed
+	if len(errs) == 1 {
+		code = errs[0].Code
+	}
+	details := make([]string, len(errs))
+	for i, e := range errs {
+		details[i] = e.Detail
+	}
+	return newProblem(code, strings.Join(details, "; "), requestID, errs...)
+}
+
+func mapError(c echo.Context, err error) error {
+	requestID, _ := c.Get("request_id").(string)
+	return writeProblem(c, problemFor(err, requestID))
+}
+
+// domainProblems maps domain errors to codes; field names the request
+// field at fault, if any.
+var domainProblems = []struct {
+	err   error
+	code  string
+	field string
+}{
+	{domain.ErrDeckNotFound, codeDeckNotFound, ""},
+	{domain.ErrReadingNotFound, codeReadingNotFound, ""},
+	{domain.ErrInvalidN, codeInvalidN, "n"},
+	{domain.ErrNExceedsDeck, codeNExceedsDeck, "n"},
+	{domain.ErrUnknownStyle, codeUnknownStyle, "style"},
+	{domain.ErrInvalidLength, codeInvalidLength, "length"},
+	{domain.ErrQuestionRequired, codeQuestionRequired, "question"},
+	{domain.ErrInvalidPosition, codeInvalidPosition, "position"},
+	{domain.ErrInvalidClarifierCount, codeInvalidClarifierCount, "count"},
+	{domain.ErrFollowUpLimit, codeFollowUpLimit, ""},
+	{domain.ErrNoCardsLeft, codeNoCardsLeft, ""},
+	{domain.ErrTableChanged, codeReadingChanged, ""},
+	{domain.ErrBudgetExceeded, codeBudgetExceeded, ""},
+	{domain.ErrRateLimited, codeRateLimited, ""},
+}
+
+// problemFor maps an application error to a problem that is safe to show
+// to clients, logging errors that are not the client's fault.
+func problemFor(err error, requestID string) Problem {
+	for _, d := range domainProblems {
+		if !errors.Is(err, d.err) {
+			continue
+		}
+		if d.field == "" {
+			return newProblem(d.code, err.Error(), requestID)
+		}
+		return newProblem(d.code, err.Error(), requestID, FieldError{Field: d.field, Code: d.code, Detail: err.Error()})
+	}
+
+	if errors.Is(err, domain.ErrUpstreamLLM) || errors.Is(err, domain.ErrInvalidLLMJSON) {
+		slog.Error("upstream LLM failure", "request_id", requestID, "error", err)
+		return newProblem(codeUpstreamLLM, "the interpretation service failed; try again", requestID)
+	}
+	slog.Error("internal error", "request_id", requestID, "error", err)
+	return newProblem(codeInternal, "", requestID)
+}
+
+// ProblemErrorHandler renders errors that reach Echo, such as unknown
+// routes, as problems.
+func ProblemErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	var he *echo.HTTPError
+	if !errors.As(err, &he) {
+		_ = mapError(c, err)
+		return
+	}
+
+	code := codeInternal
+	switch {
+	case he.Code == http.StatusNotFound:
+		code = codeNotFound
+	case he.Code == http.StatusMethodNotAllowed:
+		code = codeMethodNotAllowed
+	case he.Code == http.StatusUnauthorized:
+		code = codeUnauthorized
+	case he.Code >= 400 && he.Code < 500:
+		code = codeBadRequest
+	}
+	requestID, _ := c.Get("request_id").(string)
+	p := newProblem(code, "", requestID)
+	if msg, ok := he.Message.(string); ok && code != codeInternal {
+		p.Detail = msg
+	}
+	// Keep the status Echo chose, e.g. 413 from a body limit.
+	p.Status = he.Code
+	if c.Request().Method == http.MethodHead {
+		_ = c.NoContent(p.Status)
+		return
+	}
+	_ = writeProblem(c, p)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+
+	"github.com/randomtoy/taas-go/api"
+	"github.com/randomtoy/taas-go/internal/domain"
+)
+
+func TestProblemFor(t *testing.T) {
+	tests := []struct {
+		err       error
+		status    int
+		code      string
+		field     string
+		retryable bool
+	}{
+		{fmt.Errorf("generate spread: %w", domain.ErrInvalidN), http.StatusBadRequest, "invalid_n", "n", false},
+		{fmt.Errorf("get deck: %w", domain.ErrDeckNotFound), http.StatusNotFound, "deck_not_found", "", false},
+		{domain.ErrNoCardsLeft, http.StatusConflict, "no_cards_left", "", false},
+		{fmt.Errorf("save clarifiers: %w", domain.ErrTableChanged), http.StatusConflict, "reading_changed", "", true},
+		{domain.ErrBudgetExceeded, http.StatusTooManyRequests, "budget_exceeded", "", true},
+		{fmt.Errorf("interpret: %w", domain.ErrInvalidLLMJSON), http.StatusBadGateway, "upstream_llm_failure", "", true},
+		{errors.New("db password leaked"), http.StatusInternalServerError, "internal_error", "", false},
+	}
+	for _, tt := range tests {
+		p := problemFor(tt.err, "req-1")
+		if p.Status != tt.status || p.Code != tt.code || p.Retryable != tt.retryable {
+			t.Errorf("%v: got %d %s retryable=%v", tt.err, p.Status, p.Code, p.Retryable)
+		}
+		if p.Type != problemTypePrefix+tt.code || p.Title == "" || p.RequestID != "req-1" {
+			t.Errorf("%v: incomplete problem %+v", tt.err, p)
+		}
+		if tt.field != "" && (len(p.Errors) != 1 || p.Errors[0].Field != tt.field) {
+			t.Errorf("%v: field errors = %+v, want %s", tt.err, p.Errors, tt.field)
+		}
+		if tt.code == codeInternal && p.Detail != "" {
+			t.Errorf("%v: internal error detail leaked: %q", tt.err, p.Detail)
+		}
+	}
+}
+
+func TestProblemKinds_Complete(t *testing.T) {
+	for code, kind := range problemKinds {
+		if kind.status < 400 || kind.title == "" {
+			t.Errorf("%s: incomplete kind %+v", code, kind)
+		}
+	}
+	for _, d := range domainProblems {
+		if _, ok := problemKinds[d.code]; !ok {
+			t.Errorf("%v maps to unregistered code %s", d.err, d.code)
+		}
+	}
+}
+
+func TestValidationProblem(t *testing.T) {
+	one := validationProblem([]FieldError{errInvalidN}, "")
+	if one.Code != codeInvalidN {
+		t.Errorf("single error code = %s, want %s", one.Code, codeInvalidN)
+	}
+	two := validationProblem([]FieldError{errInvalidN, {Field: "q", Code: codeQuestionTooLong, Detail: "q must be at most 500 characters"}}, "")
+	if two.Code != codeValidationFailed || len(two.Errors) != 2 || two.Status != http.StatusBadRequest {
+		t.Errorf("unexpected grouped problem: %+v", two)
+	}
+}
+
+func TestProblemErrorHandler_UnknownRoute(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ProblemErrorHandler
+	e.GET("/v1/tarot", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/nope", nil))
+
+	if rec.Code != http.StatusNotFound || rec.Header().Get(echo.HeaderContentType) != mimeProblemJSON {
+		t.Fatalf("got %d %s", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+	var p Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Code != codeNotFound || p.Instance != "/v1/nope" {
+		t.Errorf("unexpected problem: %+v", p)
+	}
+}
+
+// TestProblemCodes_MatchSpec keeps the codes documented in api/openapi.yaml
+// and the codes the service can return in sync.
+func TestProblemCodes_MatchSpec(t *testing.T) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(api.OpenAPI, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	code := schemas["Problem"].(map[string]any)["properties"].(map[string]any)["code"].(map[string]any)
+	documented := map[string]bool{}
+	for _, c := range code["enum"].([]any) {
+		documented[c.(string)] = true
+	}
+	for c := range problemKinds {
+		if !documented[c] {
+			t.Errorf("code %s is not documented in the Problem schema", c)
+		}
+	}
+	for c := range documented {
+		if _, ok := problemKinds[c]; !ok {
+			t.Errorf("documented code %s is never returned", c)
+		}
+	}
+
+	var walk func(node any)
+	walk = func(node any) {
+		switch n := node.(type) {
+		case map[string]any:
+			if c, ok := n["x-error-code"].(string); ok && problemKinds[c].status == 0 {
+				t.Errorf("x-error-code %s is not a registered code", c)
+			}
+			if codes, ok := n["x-error-codes"].(map[string]any); ok {
+				for _, c := range codes {
+					if problemKinds[c.(string)].status == 0 {
+						t.Errorf("x-error-codes value %s is not a registered code", c)
+					}
+				}
+			}
+			for _, v := range n {
+				walk(v)
+			}
+		case []any:
+			for _, v := range n {
+				walk(v)
+			}
+		}
+	}
+	walk(doc)
+}
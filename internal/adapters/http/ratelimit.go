@@ -0,0 +1,58 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/internal/ratelimit"
+)
+
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// RateLimitMiddleware limits requests to the public /v1 API by client IP
+// and in total. Routes are keyed by their Echo path, e.g.
+// "/v1/readings/:id/followups". Probes and admin endpoints are never
+// limited.
+func RateLimitMiddleware(limiter *ratelimit.Limiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !strings.HasPrefix(c.Request().URL.Path, "/v1/") {
+				return next(c)
+			}
+			d := limiter.Allow(c.Path(), c.RealIP())
+			if !d.Rate.IsZero() {
+				h := c.Response().Header()
+				h.Set(headerRateLimitLimit, strconv.Itoa(d.Rate.N))
+				h.Set(headerRateLimitRemaining, strconv.Itoa(d.Remaining))
+				h.Set(headerRateLimitReset, strconv.Itoa(ratelimit.RetryAfterSeconds(d.Reset)))
+				h.Set(headerRateLimitPolicy, fmt.Sprintf("%d;w=%d", d.Rate.N, ratelimit.RetryAfterSeconds(d.Rate.Period)))
+			}
+			if !d.Allowed {
+				c.Response().Header().Set(headerRetryAfter, strconv.Itoa(ratelimit.RetryAfterSeconds(d.RetryAfter)))
+				return problem(c, codeRateLimited, "too many requests; retry after the delay in Retry-After")
+			}
+			return next(c)
+		}
+	}
+}
+
+// IPExtractor reads the client IP from X-Forwarded-For, trusting hops
+// from loopback, private networks and the given proxy ranges, such as the
+// Traefik ingress.
+func IPExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	opts := make([]echo.TrustOption, 0, len(trustedProxies))
+	for _, n := range trustedProxies {
+		opts = append(opts, echo.TrustIPRange(n))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...)
+}
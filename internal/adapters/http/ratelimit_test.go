@@ -0,0 +1,123 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/api"
+	"github.com/randomtoy/taas-go/internal/adapters/decks"
+	httpadapter "github.com/randomtoy/taas-go/internal/adapters/http"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/template"
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/openapi"
+	"github.com/randomtoy/taas-go/internal/ratelimit"
+)
+
+func newRateLimitedEcho(limits ratelimit.Limits, trusted ...*net.IPNet) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = httpadapter.ProblemErrorHandler
+	e.IPExtractor = httpadapter.IPExtractor(trusted)
+	e.Use(httpadapter.RateLimitMiddleware(ratelimit.NewLimiter(limits)))
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "OK") }
+	e.GET("/v1/tarot", ok)
+	e.GET("/livez", ok)
+	return e
+}
+
+func get(e *echo.Echo, path, remoteAddr, xff string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	if xff != "" {
+		req.Header.Set(echo.HeaderXForwardedFor, xff)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	e := newRateLimitedEcho(ratelimit.Limits{PerIP: ratelimit.Rate{N: 1, Period: time.Minute}})
+
+	rec := get(e, "/v1/tarot", "203.0.113.1:1000", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request: %d", rec.Code)
+	}
+	if rec.Header().Get("RateLimit-Limit") != "1" || rec.Header().Get("RateLimit-Remaining") != "0" || rec.Header().Get("RateLimit-Policy") != "1;w=60" {
+		t.Errorf("unexpected headers: %v", rec.Header())
+	}
+
+	rec = get(e, "/v1/tarot", "203.0.113.1:1000", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %q", rec.Header().Get("Retry-After"))
+	}
+
+	for range 3 {
+		if rec := get(e, "/livez", "203.0.113.1:1000", ""); rec.Code != http.StatusOK {
+			t.Fatal("probe was rate limited")
+		}
+	}
+}
+
+func TestRateLimitMiddleware_ForwardedFor(t *testing.T) {
+	_, traefik, _ := net.ParseCIDR("198.51.100.0/24")
+	e := newRateLimitedEcho(ratelimit.Limits{PerIP: ratelimit.Rate{N: 1, Period: time.Minute}}, traefik)
+
+	// Two clients behind the trusted proxy get their own buckets.
+	if rec := get(e, "/v1/tarot", "198.51.100.7:1000", "203.0.113.1"); rec.Code != http.StatusOK {
+		t.Fatalf("client 1: %d", rec.Code)
+	}
+	if rec := get(e, "/v1/tarot", "198.51.100.7:1000", "203.0.113.2"); rec.Code != http.StatusOK {
+		t.Fatalf("client 2: %d", rec.Code)
+	}
+
+	// An untrusted peer cannot pick its identity with the header.
+	if rec := get(e, "/v1/tarot", "192.0.2.1:1000", "203.0.113.3"); rec.Code != http.StatusOK {
+		t.Fatalf("untrusted peer: %d", rec.Code)
+	}
+	if rec := get(e, "/v1/tarot", "192.0.2.1:1000", "203.0.113.4"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For bypassed the limit: %d", rec.Code)
+	}
+}
+
+func TestBatch_ItemsCountAgainstRateLimit(t *testing.T) {
+	spec, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := ratelimit.NewLimiter(ratelimit.Limits{Routes: map[string]ratelimit.Rate{"/v1/readings/batch": {N: 2, Period: time.Hour}}})
+	svc := app.NewTarotService(decks.NewEmbeddedStore(), template.NewInterpreter(), &seqRNG{}, "template")
+	e := echo.New()
+	e.Use(httpadapter.RateLimitMiddleware(limiter))
+	httpadapter.NewBatchHandler(svc, spec, limiter, 10).Register(e)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/readings/batch", strings.NewReader(`{"items": [{"n": 1}, {"n": 0}, {"n": 1}, {"n": 1}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var res struct {
+		Results []struct {
+			Status int `json:"status"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || len(res.Results) != 4 {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+	// The invalid item costs nothing; the last valid one is over the limit.
+	want := []int{http.StatusOK, http.StatusBadRequest, http.StatusOK, http.StatusTooManyRequests}
+	for i, r := range res.Results {
+		if r.Status != want[i] {
+			t.Errorf("item %d: status %d, want %d", i, r.Status, want[i])
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/randomtoy/taas-go/internal/openapi"
+)
+
+// OpenAPIValidator rejects requests that do not match api/openapi.yaml with
+// a 400 problem before they reach a handler, and bodies over maxBodyBytes
+// with a 413 problem. Bodies it leaves to the handler stay limited, so
+// handlers check reads with bodyTooLarge. Requests the document does not
+// describe are passed through. If onResponseMismatch is not nil, responses
+// are checked too and every mismatch is reported to it; this is meant for
+// tests and debugging, not production traffic.
+func OpenAPIValidator(spec *openapi.Spec, maxBodyBytes int64, onResponseMismatch func(c echo.Context, operationID string, problems []string)) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			op, pathParams := spec.Find(req.Method, req.URL.Path)
+			if op == nil {
+				return next(c)
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBodyBytes)
+
+			errs, err := op.ValidateRequest(req, pathParams)
+			if ok, p := bodyTooLarge(c, err); ok {
+				return p
+			}
+			switch {
+			case errors.Is(err, openapi.ErrMalformedBody):
+				return problem(c, codeInvalidRequestBody, err.Error())
+			case err != nil:
+				return problem(c, codeInvalidRequestBody, "the body could not be read")
+			case len(errs) > 0:
+				return invalid(c, toFieldErrors(errs))
+			}
+
+			if onResponseMismatch == nil {
+				return next(c)
+			}
+			rec := &recordingWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			err = next(c)
+			c.Response().Writer = rec.ResponseWriter
+			if err != nil {
+				// The error handler writes the response after this returns.
+				return err
+			}
+			res := c.Response()
+			if problems := op.ValidateResponse(res.Status, res.Header().Get(echo.HeaderContentType), rec.body.Bytes()); len(problems) > 0 {
+				onResponseMismatch(c, op.ID, problems)
+			}
+			return nil
+		}
+	}
+}
+
+// bodyTooLarge writes a 413 problem if err comes from reading a body past
+// the limit OpenAPIValidator set, and reports whether it did.
+func bodyTooLarge(c echo.Context, err error) (bool, error) {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false, nil
+	}
+	return true, problem(c, codeBodyTooLarge, fmt.Sprintf("the request body may be at most %d bytes", tooLarge.Limit))
+}
+
+// toFieldErrors gives spec violations without an x-error-code a generic
+// code.
+func toFieldErrors(errs []openapi.FieldError) []FieldError {
+	out := make([]FieldError, len(errs))
+	for i, e := range errs {
+		code := e.Code
+		if code == "" {
+			code = codeInvalidParameter
+			if e.In == "body" {
+				code = codeInvalidField
+			}
+		}
+		out[i] = FieldError{Field: e.Field, Code: code, Detail: e.Detail}
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+// Package anthropic talks to the Anthropic Messages API.
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/chat"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/prompts"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// DefaultVersion is the API version sent when none is configured.
+const DefaultVersion = "2023-06-01"
+
+// defaultMaxTokens is sent when no limit is configured, since the Messages
+// API requires one. It leaves room for a ten-card reading.
+const defaultMaxTokens = 4096
+
+// Client implements ports.Interpreter via the Anthropic Messages API.
+type Client struct {
+	httpClient     *http.Client
+	apiKey         string
+	baseURL        string
+	version        string
+	model          string
+	fallbackModels []string
+	params         chat.Params
+	prompts        *prompts.Set
+	logger         *slog.Logger
+}
+
+// Option customizes a Client.
+type Option func(*Client)
+
+// WithPrompts renders prompts from set instead of the embedded defaults.
+func WithPrompts(set *prompts.Set) Option {
+	return func(c *Client) { c.prompts = set }
+}
+
+// WithParams sets the generation parameters sent with messages. The
+// Messages API has no seed, so seeds are ignored.
+func WithParams(params chat.Params) Option {
+	return func(c *Client) { c.params = params }
+}
+
+// WithVersion sets the anthropic-version header.
+func WithVersion(version string) Option {
+	return func(c *Client) { c.version = version }
+}
+
+// NewClient returns a client for the API at baseURL, normally
+// "https://api.anthropic.com/v1".
+func NewClient(httpClient *http.Client, apiKey, baseURL, model string, fallbackModels []string, logger *slog.Logger, opts ...Option) *Client {
+	c := &Client{
+		httpClient:     httpClient,
+		apiKey:         apiKey,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		version:        DefaultVersion,
+		model:          model,
+		fallbackModels: fallbackModels,
+		prompts:        prompts.Default(),
+		logger:         logger,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// messagesRequest / messagesResponse mirror the Messages API shapes.
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type messagesRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	TopP        *float64  `json:"top_p,omitempty"`
+}
+
+type messagesResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (c *Client) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	models := make([]string, 0, 1+len(c.fallbackModels))
+	models = append(models, c.model)
+	models = append(models, c.fallbackModels...)
+
+	// The Messages API has no JSON Schema response format; replies are
+	// validated and repaired like any other.
+	r := chat.Runner{Complete: c.complete, Models: models, Prompts: c.prompts, Params: c.params, Logger: c.logger}
+	return r.Interpret(ctx, in)
+}
+
+// complete sends req as a Messages API request, with the system prompt as
+// its top-level field.
+func (c *Client) complete(ctx context.Context, req chat.Completion) (string, ports.Usage, error) {
+	body := messagesRequest{
+		Model:       req.Model,
+		System:      req.System,
+		Messages:    make([]message, len(req.Messages)),
+		MaxTokens:   req.Params.MaxTokens,
+		Temperature: req.Params.Temperature,
+		TopP:        req.Params.TopP,
+	}
+	if body.MaxTokens == 0 {
+		body.MaxTokens = defaultMaxTokens
+	}
+	for i, m := range req.Messages {
+		body.Messages[i] = message{Role: m.Role, Content: m.Content}
+	}
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return "", ports.Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/messages", bytes.NewReader(raw))
+	if err != nil {
+		return "", ports.Usage{}, fmt.Errorf("build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	c.setHeaders(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return "", ports.Usage{}, fmt.Errorf("http call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", ports.Usage{}, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", ports.Usage{}, fmt.Errorf("upstream status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var msg messagesResponse
+	if err := json.Unmarshal(respBody, &msg); err != nil {
+		return "", ports.Usage{}, fmt.Errorf("decode response: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range msg.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", ports.Usage{}, fmt.Errorf("no text in response (stop reason %q)", msg.StopReason)
+	}
+
+	usage := ports.Usage{
+		PromptTokens:     msg.Usage.InputTokens,
+		CompletionTokens: msg.Usage.OutputTokens,
+		TotalTokens:      msg.Usage.InputTokens + msg.Usage.OutputTokens,
+	}
+	return strings.TrimSpace(text.String()), usage, nil
+}
+
+// Check lists the available models, a cheap call that confirms the API is
+// reachable and the key is accepted.
+func (c *Client) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http call: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upstream status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", c.version)
+}
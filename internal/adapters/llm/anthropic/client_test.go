@@ -0,0 +1,192 @@
+package anthropic_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/anthropic"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func testInput() ports.InterpretInput {
+	return ports.InterpretInput{
+		DeckID:   "major_arcana",
+		Spread:   "three_card",
+		Question: "What lies ahead?",
+		Cards: []ports.CardInput{
+			{Name: "The Fool", Position: 1, Orientation: "upright", Keywords: []string{"beginnings"}, Short: "A fresh start."},
+			{Name: "The Magician", Position: 2, Orientation: "reversed", Keywords: []string{"willpower"}, Short: "Personal power."},
+			{Name: "The Star", Position: 3, Orientation: "upright", Keywords: []string{"hope"}, Short: "Renewed faith."},
+		},
+		Lang: "en",
+	}
+}
+
+func validJSON() string {
+	b, _ := json.Marshal(ports.InterpretOutput{
+		Text: "A thoughtful interpretation.",
+		Cards: []ports.CardInterpretation{
+			{Position: 1, Text: "A leap into the unknown."},
+			{Position: 2, Text: "Skills held back."},
+			{Position: 3, Text: "Quiet hope returns."},
+		},
+		Questions: []string{"What are you ready to begin?"},
+		Style:     "neutral",
+	})
+	return string(b)
+}
+
+// reply writes a Messages API response whose text is split over two
+// content blocks, as the API may do.
+func reply(w http.ResponseWriter, text string) {
+	half := len(text) / 2
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"content": []map[string]any{
+			{"type": "text", "text": text[:half]},
+			{"type": "text", "text": text[half:]},
+		},
+		"stop_reason": "end_turn",
+		"usage":       map[string]any{"input_tokens": 100, "output_tokens": 50},
+	})
+}
+
+func TestClient_Interpret_Success(t *testing.T) {
+	var gotReq map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/messages" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropic.DefaultVersion {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected Authorization header")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		reply(w, validJSON())
+	}))
+	defer srv.Close()
+
+	client := anthropic.NewClient(srv.Client(), "test-key", srv.URL, "claude-test", nil, slog.Default())
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Text != "A thoughtful interpretation." || out.Model != "claude-test" {
+		t.Errorf("unexpected output: %+v", out)
+	}
+	if out.Usage != (ports.Usage{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150}) {
+		t.Errorf("unexpected usage: %+v", out.Usage)
+	}
+
+	if system, _ := gotReq["system"].(string); system == "" {
+		t.Errorf("system prompt not sent as a top-level field: %v", gotReq)
+	}
+	if gotReq["max_tokens"] != 4096.0 {
+		t.Errorf("max_tokens = %v", gotReq["max_tokens"])
+	}
+	messages, _ := gotReq["messages"].([]any)
+	if len(messages) != 1 {
+		t.Fatalf("expected one user message, got %v", messages)
+	}
+	if role := messages[0].(map[string]any)["role"]; role != "user" {
+		t.Errorf("first message role = %v", role)
+	}
+}
+
+func TestClient_Interpret_RepairsInvalidJSON(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			reply(w, "Here is your reading: the cards look promising.")
+			return
+		}
+		reply(w, validJSON())
+	}))
+	defer srv.Close()
+
+	client := anthropic.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls (original + repair), got %d", calls)
+	}
+	if out.Usage.TotalTokens != 300 {
+		t.Errorf("usage not summed across the repair: %+v", out.Usage)
+	}
+}
+
+func TestClient_Interpret_FallbackModel(t *testing.T) {
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Model string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		if req.Model == "primary" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error"}}`))
+			return
+		}
+		reply(w, validJSON())
+	}))
+	defer srv.Close()
+
+	client := anthropic.NewClient(srv.Client(), "key", srv.URL, "primary", []string{"fallback"}, slog.Default())
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Model != "fallback" || len(models) != 2 {
+		t.Errorf("model = %s, calls = %v", out.Model, models)
+	}
+}
+
+func TestClient_Interpret_UpstreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	client := anthropic.NewClient(srv.Client(), "bad", srv.URL, "model", nil, slog.Default())
+
+	_, err := client.Interpret(context.Background(), testInput())
+	if !errors.Is(err, domain.ErrUpstreamLLM) {
+		t.Fatalf("expected ErrUpstreamLLM, got %v", err)
+	}
+}
+
+func TestClient_Check(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "key" {
+			t.Errorf("unexpected x-api-key %q", r.Header.Get("x-api-key"))
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	client := anthropic.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+	if err := client.Check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	status = http.StatusUnauthorized
+	if err := client.Check(context.Background()); err == nil {
+		t.Fatal("expected error for 401")
+	}
+}
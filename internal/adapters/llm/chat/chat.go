@@ -0,0 +1,135 @@
+// Package chat runs the interpretation exchange shared by the LLM provider
+// adapters: render the prompts, ask each model in turn, validate the JSON
+// it returns and, once per model, ask again with a repair prompt. Adapters
+// only supply the call that sends one completion to their API.
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/llmjson"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/prompts"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+const disclaimer = "For reflection/entertainment; not medical/legal/financial advice."
+
+// Completion is one request to a chat model.
+type Completion struct {
+	Model    string
+	System   string
+	Messages []ports.Message // user and assistant turns, oldest first
+	Schema   map[string]any  // response JSON Schema when structured output is on, else nil
+	Params   ports.GenerationParams
+}
+
+// CompleteFunc sends a completion and returns the reply text and the tokens
+// it used.
+type CompleteFunc func(ctx context.Context, req Completion) (string, ports.Usage, error)
+
+// Runner interprets readings through Complete.
+type Runner struct {
+	Complete   CompleteFunc
+	Models     []string // the primary model, then fallbacks in order
+	Prompts    *prompts.Set
+	Params     Params
+	Structured bool
+	Logger     *slog.Logger
+}
+
+// Interpret returns the first valid interpretation from Models, or the last
+// model's error. The tokens of every call, including rejected replies,
+// repairs and failed models, are in the output's Spend or, on failure, in
+// a *ports.SpendError.
+func (r *Runner) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	var lastErr error
+	var spend []ports.ModelUsage
+	for _, model := range r.Models {
+		out, usage, err := r.interpretWithModel(ctx, in, model)
+		if usage != (ports.Usage{}) {
+			spend = append(spend, ports.ModelUsage{Model: model, Usage: usage})
+		}
+		if err == nil {
+			out.Spend = spend
+			return out, nil
+		}
+		lastErr = err
+		if len(r.Models) > 1 {
+			r.Logger.WarnContext(ctx, "model failed, trying next", "model", model, "error", err)
+		}
+	}
+
+	if spend != nil {
+		lastErr = &ports.SpendError{Spend: spend, Err: lastErr}
+	}
+	return ports.InterpretOutput{}, lastErr
+}
+
+// interpretWithModel also returns the tokens model used, even on failure.
+func (r *Runner) interpretWithModel(ctx context.Context, in ports.InterpretInput, model string) (ports.InterpretOutput, ports.Usage, error) {
+	var usage ports.Usage
+	systemPrompt, err := r.Prompts.System(in)
+	if err != nil {
+		return ports.InterpretOutput{}, usage, fmt.Errorf("build system prompt: %w", err)
+	}
+	turns, err := r.Prompts.Conversation(in)
+	if err != nil {
+		return ports.InterpretOutput{}, usage, fmt.Errorf("build user prompt: %w", err)
+	}
+
+	req := Completion{Model: model, System: systemPrompt, Messages: turns, Params: r.Params.For(model, in)}
+	if r.Structured {
+		req.Schema = llmjson.Schema(in)
+	}
+
+	content, u, err := r.Complete(ctx, req)
+	addUsage(&usage, u)
+	if err != nil {
+		return ports.InterpretOutput{}, usage, fmt.Errorf("%w: %w", domain.ErrUpstreamLLM, err)
+	}
+
+	out, err := llmjson.Parse(content, in, systemPrompt)
+	if err != nil {
+		r.Logger.WarnContext(ctx, "LLM response failed validation, retrying", "model", model, "error", err)
+		repair, perr := r.Prompts.Retry(in, content, llmjson.Problems(err))
+		if perr != nil {
+			return ports.InterpretOutput{}, usage, fmt.Errorf("build retry prompt: %w", perr)
+		}
+		// The repair prompt replaces the last question; earlier turns stay
+		// so a follow-up is still answered in context.
+		req.Messages = append(turns[:len(turns)-1], ports.Message{Role: ports.RoleUser, Content: repair})
+		content, u, err = r.Complete(ctx, req)
+		addUsage(&usage, u)
+		if err != nil {
+			return ports.InterpretOutput{}, usage, fmt.Errorf("%w: %w", domain.ErrUpstreamLLM, err)
+		}
+		out, err = llmjson.Parse(content, in, systemPrompt)
+		if err != nil {
+			return ports.InterpretOutput{}, usage, fmt.Errorf("%w: %w", domain.ErrInvalidLLMJSON, err)
+		}
+	}
+
+	if out.Disclaimer == "" {
+		out.Disclaimer = disclaimer
+	}
+	out.Model = model
+	out.Usage = usage
+	out.PromptVersion = r.Prompts.Version()
+
+	r.Logger.InfoContext(ctx, "interpretation generated",
+		"model", model,
+		"prompt_version", out.PromptVersion,
+		"total_tokens", usage.TotalTokens,
+	)
+
+	return out, usage, nil
+}
+
+func addUsage(to *ports.Usage, u ports.Usage) {
+	to.PromptTokens += u.PromptTokens
+	to.CompletionTokens += u.CompletionTokens
+	to.TotalTokens += u.TotalTokens
+}
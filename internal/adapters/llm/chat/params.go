@@ -0,0 +1,54 @@
+package chat
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Params are the generation parameters for each completion: Default,
+// overridden field by field by the model's entry in Models, then by the
+// request's length and seed.
+type Params struct {
+	Default         ports.GenerationParams
+	Models          map[string]ports.GenerationParams
+	LengthMaxTokens map[string]int // max tokens for a requested length, e.g. "short"
+}
+
+// For returns the parameters to send to model for in.
+func (p Params) For(model string, in ports.InterpretInput) ports.GenerationParams {
+	out := p.Default
+	if m, ok := p.Models[model]; ok {
+		if m.Temperature != nil {
+			out.Temperature = m.Temperature
+		}
+		if m.TopP != nil {
+			out.TopP = m.TopP
+		}
+		if m.MaxTokens > 0 {
+			out.MaxTokens = m.MaxTokens
+		}
+		if m.Seed != nil {
+			out.Seed = m.Seed
+		}
+	}
+	if n := p.LengthMaxTokens[in.Length]; n > 0 {
+		out.MaxTokens = n
+	}
+	if in.Seed != nil {
+		seed := ProviderSeed(*in.Seed)
+		out.Seed = &seed
+	}
+	return out
+}
+
+// ProviderSeed derives the seed sent to a provider from a reading seed. Any
+// int64 is hashed into 31 bits, which every provider that takes a seed
+// accepts.
+func ProviderSeed(readingSeed int64) int64 {
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.BigEndian, readingSeed)
+	return int64(h.Sum64() & math.MaxInt32)
+}
@@ -0,0 +1,53 @@
+package chat_test
+
+import (
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/chat"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func TestParams_For(t *testing.T) {
+	temperature, cold, topP, seed := 0.8, 0.1, 0.9, int64(1)
+	p := chat.Params{
+		Default: ports.GenerationParams{Temperature: &temperature, TopP: &topP, MaxTokens: 1000, Seed: &seed},
+		Models: map[string]ports.GenerationParams{
+			"small": {Temperature: &cold, MaxTokens: 400},
+		},
+		LengthMaxTokens: map[string]int{"long": 2400},
+	}
+
+	got := p.For("big", ports.InterpretInput{})
+	if *got.Temperature != 0.8 || got.MaxTokens != 1000 || *got.Seed != 1 {
+		t.Errorf("defaults: %+v", got)
+	}
+
+	got = p.For("small", ports.InterpretInput{})
+	if *got.Temperature != 0.1 || *got.TopP != 0.9 || got.MaxTokens != 400 {
+		t.Errorf("model entry should override only the fields it sets: %+v", got)
+	}
+
+	readingSeed := int64(123456789012)
+	got = p.For("small", ports.InterpretInput{Length: "long", Seed: &readingSeed})
+	if got.MaxTokens != 2400 || *got.Seed != chat.ProviderSeed(readingSeed) {
+		t.Errorf("request overrides: %+v", got)
+	}
+	if got = p.For("small", ports.InterpretInput{Length: "short"}); got.MaxTokens != 400 {
+		t.Errorf("a length without a limit should keep the model's: %+v", got)
+	}
+}
+
+func TestProviderSeed(t *testing.T) {
+	for _, seed := range []int64{0, 1, -1, 1 << 62} {
+		got := chat.ProviderSeed(seed)
+		if got < 0 || got > 1<<31-1 {
+			t.Errorf("ProviderSeed(%d) = %d, outside 31 bits", seed, got)
+		}
+		if got != chat.ProviderSeed(seed) {
+			t.Errorf("ProviderSeed(%d) is not stable", seed)
+		}
+	}
+	if chat.ProviderSeed(1) == chat.ProviderSeed(2) {
+		t.Error("different reading seeds gave the same provider seed")
+	}
+}
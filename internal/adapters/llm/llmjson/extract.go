@@ -0,0 +1,30 @@
+package llmjson
+
+import "strings"
+
+// Extract strips the wrappers models commonly put around a JSON object:
+// markdown code fences (with or without a language tag) and prose before
+// or after the object. Content without a recognisable object is returned
+// trimmed so the parse error shows what the model actually said.
+func Extract(content string) string {
+	s := strings.TrimSpace(content)
+
+	if start := strings.Index(s, "```"); start >= 0 {
+		body := s[start+3:]
+		// Drop the info string, e.g. "json".
+		if nl := strings.IndexByte(body, '\n'); nl >= 0 && !strings.Contains(body[:nl], "{") {
+			body = body[nl+1:]
+		}
+		if end := strings.Index(body, "```"); end >= 0 {
+			body = body[:end]
+		}
+		s = strings.TrimSpace(body)
+	}
+
+	first := strings.IndexByte(s, '{')
+	last := strings.LastIndexByte(s, '}')
+	if first >= 0 && last > first {
+		return s[first : last+1]
+	}
+	return s
+}
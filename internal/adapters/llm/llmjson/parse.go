@@ -0,0 +1,76 @@
+// Package llmjson decodes and checks the JSON interpretation returned by an
+// LLM. It is shared by the provider adapters so they repair and reject
+// responses the same way.
+package llmjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/randomtoy/taas-go/internal/jsonschema"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// ValidationError lists every reason a response could not be used. The
+// problems are phrased so they can be sent back to the model verbatim.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid interpretation: " + strings.Join(e.Problems, "; ")
+}
+
+// Problems returns the problems carried by err, or err's text if it is not
+// a *ValidationError.
+func Problems(err error) []string {
+	if ve, ok := err.(*ValidationError); ok {
+		return ve.Problems
+	}
+	return []string{err.Error()}
+}
+
+// Parse strips wrappers from content, validates it against Schema(in),
+// checks that it interprets every drawn card exactly once and that it does
+// not repeat lines of systemPrompt (a sign of successful prompt injection).
+func Parse(content string, in ports.InterpretInput, systemPrompt string) (ports.InterpretOutput, error) {
+	raw := []byte(Extract(content))
+
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return ports.InterpretOutput{}, &ValidationError{Problems: []string{"response is not valid JSON: " + err.Error()}}
+	}
+	problems := jsonschema.New(Schema(in)).Validate(doc)
+
+	var out ports.InterpretOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		// Only possible when the schema check has already failed.
+		return ports.InterpretOutput{}, &ValidationError{Problems: problems}
+	}
+	problems = append(problems, checkCoverage(out, in)...)
+	problems = append(problems, checkLeak(out, systemPrompt)...)
+	if len(problems) > 0 {
+		return ports.InterpretOutput{}, &ValidationError{Problems: problems}
+	}
+	return out, nil
+}
+
+// checkCoverage requires every drawn position to appear exactly once in
+// "cards". Unknown positions and empty notes are left to the schema.
+func checkCoverage(out ports.InterpretOutput, in ports.InterpretInput) []string {
+	var problems []string
+	seen := make(map[int]bool, len(out.Cards))
+	for _, c := range out.Cards {
+		if seen[c.Position] {
+			problems = append(problems, fmt.Sprintf("cards contains position %d more than once", c.Position))
+		}
+		seen[c.Position] = true
+	}
+	for _, c := range in.Cards {
+		if !seen[c.Position] {
+			problems = append(problems, fmt.Sprintf("cards is missing position %d (%s)", c.Position, c.Name))
+		}
+	}
+	return problems
+}
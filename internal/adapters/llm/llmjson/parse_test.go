@@ -0,0 +1,109 @@
+package llmjson_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/llmjson"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func twoCards() ports.InterpretInput {
+	return ports.InterpretInput{Cards: []ports.CardInput{
+		{Name: "The Fool", Position: 1},
+		{Name: "The Tower", Position: 2},
+	}}
+}
+
+func TestParse_AllCardsCovered(t *testing.T) {
+	out, err := llmjson.Parse(`{"text":"t","summary":"s","theme":"change","cards":[{"position":1,"text":"a"},{"position":2,"text":"b"}],"questions":["q?"],"style":"neutral","disclaimer":"d"}`, twoCards(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Theme != "change" || len(out.Cards) != 2 || len(out.Questions) != 1 {
+		t.Errorf("unexpected output: %+v", out)
+	}
+}
+
+func TestParse_CoverageProblems(t *testing.T) {
+	_, err := llmjson.Parse(`{"text":"","cards":[{"position":1,"text":""},{"position":1,"text":"x"},{"position":7,"text":"y"}],"style":"warm","extra":1}`, twoCards(), "")
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	got := strings.Join(llmjson.Problems(err), "\n")
+	for _, want := range []string{
+		"$: missing required property \"summary\"",
+		"$: unexpected property \"extra\"",
+		"$.text: must not be empty",
+		"$.cards: must contain at most 2 items, got 3",
+		"$.cards[0].text: must not be empty",
+		"$.cards[2].position: must be one of [1, 2]",
+		"$.style: must be one of [\"neutral\"]",
+		"cards contains position 1 more than once",
+		"cards is missing position 2 (The Tower)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("problems missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParse_NotJSON(t *testing.T) {
+	_, err := llmjson.Parse("sorry, I can't", twoCards(), "")
+	if err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Fatalf("expected JSON error, got %v", err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	want := `{"a":1}`
+	for _, in := range []string{
+		`{"a":1}`,
+		"```json\n{\"a\":1}\n```",
+		"```\n{\"a\":1}\n```",
+		"Sure! Here is the JSON:\n{\"a\":1}\nHope this helps.",
+		"Here you go:\n```json\n{\"a\":1}\n```\nAnything else?",
+	} {
+		if got := llmjson.Extract(in); got != want {
+			t.Errorf("Extract(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParse_SystemPromptLeak(t *testing.T) {
+	system := "You are a tarot reader.\n- Never provide medical, legal, or financial advice.\n- Be kind."
+	content := `{"text":"My rules: never provide medical,  LEGAL, or financial advice.","summary":"","theme":"","cards":[{"position":1,"text":"a"},{"position":2,"text":"b"}],"questions":[],"style":"neutral","disclaimer":""}`
+
+	_, err := llmjson.Parse(content, twoCards(), system)
+	if err == nil || !strings.Contains(err.Error(), "repeats the instructions") {
+		t.Fatalf("expected leak problem, got %v", err)
+	}
+
+	// Short lines such as "Be kind." are too common to count as a leak.
+	content = strings.Replace(content, "My rules: never provide medical,  LEGAL, or financial advice.", "Be kind to yourself.", 1)
+	if _, err := llmjson.Parse(content, twoCards(), system); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStrict_DropsUnsupportedKeywords(t *testing.T) {
+	schema := llmjson.Schema(twoCards())
+	var walk func(path string, m map[string]any)
+	walk = func(path string, m map[string]any) {
+		for k, v := range m {
+			switch k {
+			case "minItems", "maxItems", "minLength":
+				t.Errorf("%s: strict schema keeps %s", path, k)
+			}
+			if sub, ok := v.(map[string]any); ok {
+				walk(path+"."+k, sub)
+			}
+		}
+	}
+	walk("$", llmjson.Strict(schema))
+
+	cards := schema["properties"].(map[string]any)["cards"].(map[string]any)
+	if cards["maxItems"] != 2 {
+		t.Errorf("Strict must not change the schema Parse validates with: %v", cards)
+	}
+}
@@ -0,0 +1,78 @@
+package llmjson
+
+import (
+	"slices"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// SchemaName identifies the schema when it is sent as a response format.
+const SchemaName = "tarot_interpretation"
+
+// Schema returns the JSON Schema a response to in must satisfy. It is
+// specific to the request: "cards" must list exactly the drawn positions
+// and "style" must be the persona that was asked for. Every property is
+// required and no others are allowed, which is also what providers'
+// strict structured-output modes expect.
+func Schema(in ports.InterpretInput) map[string]any {
+	positions := make([]any, len(in.Cards))
+	for i, c := range in.Cards {
+		positions[i] = c.Position
+	}
+	style := string(domain.DefaultStyle)
+	if p, err := domain.LookupPersona(in.Style); err == nil {
+		style = string(p.Style)
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []any{"summary", "theme", "cards", "text", "questions", "style", "disclaimer"},
+		"properties": map[string]any{
+			"summary": map[string]any{"type": "string"},
+			"theme":   map[string]any{"type": "string"},
+			"cards": map[string]any{
+				"type":     "array",
+				"minItems": len(positions),
+				"maxItems": len(positions),
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []any{"position", "text"},
+					"properties": map[string]any{
+						"position": map[string]any{"type": "integer", "enum": positions},
+						"text":     map[string]any{"type": "string", "minLength": 1},
+					},
+				},
+			},
+			"text": map[string]any{"type": "string", "minLength": 1},
+			"questions": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string", "minLength": 1},
+			},
+			"style":      map[string]any{"type": "string", "enum": []any{style}},
+			"disclaimer": map[string]any{"type": "string"},
+		},
+	}
+}
+
+// strictUnsupported are the keywords OpenAI's strict structured-output mode
+// rejects. Parse still enforces them after decoding.
+var strictUnsupported = []string{"minItems", "maxItems", "minLength"}
+
+// Strict returns a copy of schema without the keywords strict
+// structured-output modes reject.
+func Strict(schema map[string]any) map[string]any {
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		if slices.Contains(strictUnsupported, k) {
+			continue
+		}
+		if m, ok := v.(map[string]any); ok {
+			v = Strict(m)
+		}
+		out[k] = v
+	}
+	return out
+}
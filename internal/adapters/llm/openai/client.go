@@ -0,0 +1,271 @@
+// Package openai talks to OpenAI-compatible chat completion APIs, such as
+// OpenRouter, vLLM, llama.cpp server, LM Studio and Azure OpenAI.
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/chat"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/llmjson"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/prompts"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Client implements ports.Interpreter via an OpenAI-compatible API.
+type Client struct {
+	httpClient     *http.Client
+	apiKey         string
+	authHeader     string
+	authScheme     string
+	headers        map[string]string
+	baseURL        string
+	query          string
+	model          string
+	fallbackModels []string
+	params         chat.Params
+	prompts        *prompts.Set
+	structured     bool
+	checkPath      string
+	logger         *slog.Logger
+}
+
+// Option customizes a Client.
+type Option func(*Client)
+
+// WithPrompts renders prompts from set instead of the embedded defaults.
+func WithPrompts(set *prompts.Set) Option {
+	return func(c *Client) { c.prompts = set }
+}
+
+// WithStructuredOutput sends the response JSON Schema as response_format so
+// providers that support structured outputs constrain the model up front.
+// Responses are validated against the same schema either way.
+func WithStructuredOutput(enabled bool) Option {
+	return func(c *Client) { c.structured = enabled }
+}
+
+// WithAuth sends the API key in header, prefixed by scheme and a space
+// unless scheme is empty. The default is "Authorization: Bearer <key>";
+// Azure OpenAI wants WithAuth("api-key", "").
+func WithAuth(header, scheme string) Option {
+	return func(c *Client) { c.authHeader, c.authScheme = header, scheme }
+}
+
+// WithHeaders adds headers to every request, e.g. an organisation or
+// routing header some deployments require.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) { c.headers = headers }
+}
+
+// WithParams sets the generation parameters sent with completions.
+func WithParams(params chat.Params) Option {
+	return func(c *Client) { c.params = params }
+}
+
+// WithCheckPath sets the path Check requests, "/models" by default. The
+// path must require the API key, or a revoked key still checks healthy:
+// OpenRouter serves its model list to anyone, so it is checked with "/key".
+func WithCheckPath(path string) Option {
+	return func(c *Client) { c.checkPath = path }
+}
+
+// NewClient returns a client for the API at baseURL, e.g.
+// "https://openrouter.ai/api/v1" or "http://localhost:8000/v1". A query
+// string on baseURL, such as Azure's "?api-version=2024-06-01", is kept on
+// every request. With an empty apiKey no auth header is sent, as local
+// servers usually expect.
+func NewClient(httpClient *http.Client, apiKey, baseURL, model string, fallbackModels []string, logger *slog.Logger, opts ...Option) *Client {
+	baseURL, query, _ := strings.Cut(baseURL, "?")
+	c := &Client{
+		httpClient:     httpClient,
+		apiKey:         apiKey,
+		authHeader:     "Authorization",
+		authScheme:     "Bearer",
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		query:          query,
+		model:          model,
+		fallbackModels: fallbackModels,
+		prompts:        prompts.Default(),
+		checkPath:      "/models",
+		logger:         logger,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// chatRequest / chatResponse mirror the OpenAI-compatible API shapes.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    *float64        `json:"temperature,omitempty"`
+	TopP           *float64        `json:"top_p,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	Seed           *int64          `json:"seed,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// responseFormat asks the provider to constrain output to a JSON Schema.
+type responseFormat struct {
+	Type       string     `json:"type"`
+	JSONSchema jsonSchema `json:"json_schema"`
+}
+
+type jsonSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage chatUsage `json:"usage"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u chatUsage) usage() ports.Usage {
+	return ports.Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, TotalTokens: u.TotalTokens}
+}
+
+func (c *Client) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	models := make([]string, 0, 1+len(c.fallbackModels))
+	models = append(models, c.model)
+	models = append(models, c.fallbackModels...)
+
+	r := chat.Runner{Complete: c.complete, Models: models, Prompts: c.prompts, Params: c.params, Structured: c.structured, Logger: c.logger}
+	return r.Interpret(ctx, in)
+}
+
+// complete sends req as an OpenAI chat completion.
+func (c *Client) complete(ctx context.Context, req chat.Completion) (string, ports.Usage, error) {
+	messages := make([]chatMessage, 0, 1+len(req.Messages))
+	messages = append(messages, chatMessage{Role: "system", Content: req.System})
+	for _, m := range req.Messages {
+		messages = append(messages, chatMessage{Role: m.Role, Content: m.Content})
+	}
+	body := chatRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		Temperature: req.Params.Temperature,
+		TopP:        req.Params.TopP,
+		MaxTokens:   req.Params.MaxTokens,
+		Seed:        req.Params.Seed,
+	}
+	if req.Schema != nil {
+		body.ResponseFormat = &responseFormat{
+			Type: "json_schema",
+			JSONSchema: jsonSchema{
+				Name:   llmjson.SchemaName,
+				Strict: true,
+				Schema: llmjson.Strict(req.Schema),
+			},
+		}
+	}
+	content, u, err := c.callLLM(ctx, body)
+	return content, u.usage(), err
+}
+
+func (c *Client) callLLM(ctx context.Context, reqBody chatRequest) (string, chatUsage, error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", chatUsage{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url("/chat/completions"), bytes.NewReader(body))
+	if err != nil {
+		return "", chatUsage{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", chatUsage{}, fmt.Errorf("http call: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", chatUsage{}, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", chatUsage{}, fmt.Errorf("upstream status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var chatResp chatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return "", chatUsage{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return "", chatUsage{}, fmt.Errorf("no choices in response")
+	}
+
+	return strings.TrimSpace(chatResp.Choices[0].Message.Content), chatResp.Usage, nil
+}
+
+// Check requests the check path, by default the model list, a cheap call
+// that confirms the API is reachable and the key is accepted.
+func (c *Client) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(c.checkPath), nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http call: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upstream status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) url(path string) string {
+	if c.query == "" {
+		return c.baseURL + path
+	}
+	return c.baseURL + path + "?" + c.query
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+	if c.apiKey == "" {
+		return
+	}
+	if c.authScheme == "" {
+		req.Header.Set(c.authHeader, c.apiKey)
+	} else {
+		req.Header.Set(c.authHeader, c.authScheme+" "+c.apiKey)
+	}
+}
@@ -0,0 +1,742 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/chat"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/openai"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/prompts"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func testInput() ports.InterpretInput {
+	return ports.InterpretInput{
+		DeckID:   "major_arcana",
+		Spread:   "three_card",
+		Question: "What lies ahead?",
+		Cards: []ports.CardInput{
+			{Name: "The Fool", Position: 1, Orientation: "upright", Keywords: []string{"beginnings"}, Short: "A fresh start."},
+			{Name: "The Magician", Position: 2, Orientation: "reversed", Keywords: []string{"willpower"}, Short: "Personal power."},
+			{Name: "The Star", Position: 3, Orientation: "upright", Keywords: []string{"hope"}, Short: "Renewed faith."},
+		},
+		Lang: "en",
+	}
+}
+
+// cardNotes covers every position drawn by testInput.
+func cardNotes() []ports.CardInterpretation {
+	return []ports.CardInterpretation{
+		{Position: 1, Text: "A leap into the unknown."},
+		{Position: 2, Text: "Skills held back."},
+		{Position: 3, Text: "Quiet hope returns."},
+	}
+}
+
+func TestClient_Interpret_Success(t *testing.T) {
+	llmResp := ports.InterpretOutput{
+		Text:       "A thoughtful interpretation.",
+		Cards:      cardNotes(),
+		Questions:  []string{"What are you ready to begin?"},
+		Style:      "neutral",
+		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
+	}
+	llmJSON, _ := json.Marshal(llmResp)
+
+	var gotReq map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Verify method and path.
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("expected /chat/completions, got %s", r.URL.Path)
+		}
+		// Verify headers.
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("bad auth header: %s", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("bad content-type: %s", r.Header.Get("Content-Type"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotReq)
+
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(
+		srv.Client(),
+		"test-key",
+		srv.URL,
+		"test-model",
+		nil,
+		slog.Default(),
+	)
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out.Text != "A thoughtful interpretation." {
+		t.Errorf("unexpected text: %s", out.Text)
+	}
+	if out.Style != "neutral" {
+		t.Errorf("unexpected style: %s", out.Style)
+	}
+
+	// Verify the request body contains our model.
+	if gotReq["model"] != "test-model" {
+		t.Errorf("request model: %v", gotReq["model"])
+	}
+}
+
+func TestClient_Interpret_BadJSON_Retry_Success(t *testing.T) {
+	llmResp := ports.InterpretOutput{
+		Text:       "Retried interpretation.",
+		Cards:      cardNotes(),
+		Questions:  []string{"What are you ready to begin?"},
+		Style:      "neutral",
+		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
+	}
+	llmJSON, _ := json.Marshal(llmResp)
+
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		callCount++
+		var content string
+		if callCount == 1 {
+			content = "this is not json at all"
+		} else {
+			content = string(llmJSON)
+		}
+
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": content}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if callCount != 2 {
+		t.Errorf("expected 2 calls (original + retry), got %d", callCount)
+	}
+	if out.Text != "Retried interpretation." {
+		t.Errorf("unexpected text: %s", out.Text)
+	}
+}
+
+func TestClient_Interpret_BadJSON_Retry_Failure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": "still not json"}},
+			},
+			"usage": map[string]any{"prompt_tokens": 90, "completion_tokens": 10, "total_tokens": 100},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", []string{"fallback"}, slog.Default())
+
+	_, err := client.Interpret(context.Background(), testInput())
+	if err == nil {
+		t.Fatal("expected error for double-bad JSON, got nil")
+	}
+	// Both models were asked twice; the rejected replies still cost tokens.
+	want := []ports.ModelUsage{
+		{Model: "model", Usage: ports.Usage{PromptTokens: 180, CompletionTokens: 20, TotalTokens: 200}},
+		{Model: "fallback", Usage: ports.Usage{PromptTokens: 180, CompletionTokens: 20, TotalTokens: 200}},
+	}
+	if got := ports.SpendOf(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("SpendOf(err) = %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_Interpret_FallbackModel(t *testing.T) {
+	llmResp := ports.InterpretOutput{
+		Text:       "Fallback interpretation.",
+		Cards:      cardNotes(),
+		Questions:  []string{"What are you ready to begin?"},
+		Style:      "neutral",
+		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
+	}
+	llmJSON, _ := json.Marshal(llmResp)
+
+	var modelsRequested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		model := req["model"].(string)
+		modelsRequested = append(modelsRequested, model)
+
+		if model == "primary-model" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":"rate limited"}`))
+			return
+		}
+
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(
+		srv.Client(), "key", srv.URL, "primary-model",
+		[]string{"fallback-model"}, slog.Default(),
+	)
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(modelsRequested) != 2 {
+		t.Errorf("expected 2 model attempts, got %d: %v", len(modelsRequested), modelsRequested)
+	}
+	if modelsRequested[0] != "primary-model" {
+		t.Errorf("first attempt should be primary-model, got %s", modelsRequested[0])
+	}
+	if modelsRequested[1] != "fallback-model" {
+		t.Errorf("second attempt should be fallback-model, got %s", modelsRequested[1])
+	}
+	if out.Text != "Fallback interpretation." {
+		t.Errorf("unexpected text: %s", out.Text)
+	}
+	if out.Model != "fallback-model" {
+		t.Errorf("expected model=fallback-model, got %s", out.Model)
+	}
+}
+
+func TestClient_Interpret_LangInPrompt(t *testing.T) {
+	llmResp := ports.InterpretOutput{
+		Text:       "Thoughtful interpretation in Russian.",
+		Cards:      cardNotes(),
+		Questions:  []string{"What are you ready to begin?"},
+		Style:      "neutral",
+		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
+	}
+	llmJSON, _ := json.Marshal(llmResp)
+
+	var gotMessages []map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		msgs, _ := req["messages"].([]any)
+		for _, m := range msgs {
+			gotMessages = append(gotMessages, m.(map[string]any))
+		}
+
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	in := testInput()
+	in.Lang = "ru"
+	_, err := client.Interpret(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify system prompt contains Russian language instruction.
+	if len(gotMessages) == 0 {
+		t.Fatal("no messages captured")
+	}
+	systemContent, _ := gotMessages[0]["content"].(string)
+	if !strings.Contains(systemContent, "Russian") {
+		t.Errorf("system prompt should mention Russian, got: %s", systemContent)
+	}
+}
+
+func TestClient_Interpret_UpstreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"boom"}`))
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	_, err := client.Interpret(context.Background(), testInput())
+	if err == nil {
+		t.Fatal("expected error for upstream 500, got nil")
+	}
+}
+
+func TestClient_Interpret_UsageSummedAcrossRetry(t *testing.T) {
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		callCount++
+		content := string(llmJSON)
+		if callCount == 1 {
+			content = "not json"
+		}
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": content}},
+			},
+			"usage": map[string]any{"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ports.Usage{PromptTokens: 200, CompletionTokens: 40, TotalTokens: 240}
+	if out.Usage != want {
+		t.Errorf("usage = %+v, want %+v", out.Usage, want)
+	}
+}
+
+func TestClient_Interpret_CustomPrompts(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "system.tmpl"), []byte("Custom system prompt."), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "VERSION"), []byte("exp-7"), 0o644)
+	set, err := prompts.Load(dir)
+	if err != nil {
+		t.Fatalf("load prompts: %v", err)
+	}
+
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+	var systemContent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		systemContent = req.Messages[0].Content
+
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default(), openai.WithPrompts(set))
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if systemContent != "Custom system prompt." {
+		t.Errorf("unexpected system prompt: %q", systemContent)
+	}
+	if out.PromptVersion != "exp-7" {
+		t.Errorf("expected prompt version exp-7, got %q", out.PromptVersion)
+	}
+}
+
+func TestClient_Interpret_WrongStyleRepaired(t *testing.T) {
+	// The model first ignores the requested persona, then corrects itself.
+	wrong, _ := json.Marshal(ports.InterpretOutput{Text: "Verses.", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+	right, _ := json.Marshal(ports.InterpretOutput{Text: "Verses.", Cards: cardNotes(), Questions: []string{}, Style: "poetic"})
+
+	var prompts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Messages[0].Content, req.Messages[1].Content)
+
+		content := string(right)
+		if len(prompts) == 2 {
+			content = string(wrong)
+		}
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": content}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	in := testInput()
+	in.Style = "poetic"
+	out, err := client.Interpret(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Style != "poetic" {
+		t.Errorf("expected style poetic, got %s", out.Style)
+	}
+	if !strings.Contains(prompts[0], "metaphor") {
+		t.Errorf("system prompt should carry the poetic persona, got: %s", prompts[0])
+	}
+	if len(prompts) != 4 || !strings.Contains(prompts[3], `$.style: must be one of ["poetic"]`) {
+		t.Errorf("expected a repair prompt naming the style violation, got: %v", prompts)
+	}
+}
+
+func TestClient_Interpret_MissingCardRepaired(t *testing.T) {
+	partial, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes()[:2], Questions: []string{}, Style: "neutral"})
+	full, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	var repairPrompt string
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		content := string(full)
+		if callCount == 1 {
+			content = string(partial)
+		} else {
+			var req struct {
+				Messages []struct {
+					Content string `json:"content"`
+				} `json:"messages"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			repairPrompt = req.Messages[1].Content
+		}
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": content}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.Cards) != 3 {
+		t.Errorf("expected 3 card notes, got %d", len(out.Cards))
+	}
+	if !strings.Contains(repairPrompt, "cards is missing position 3 (The Star)") {
+		t.Errorf("repair prompt should name the missing card, got: %s", repairPrompt)
+	}
+}
+
+func TestClient_Interpret_CodeFencedJSON(t *testing.T) {
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "Fenced.", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		callCount++
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": "Here is your reading:\n```json\n" + string(llmJSON) + "\n```"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	out, err := client.Interpret(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if callCount != 1 {
+		t.Errorf("fenced JSON should parse without a retry, got %d calls", callCount)
+	}
+	if out.Text != "Fenced." {
+		t.Errorf("unexpected text: %s", out.Text)
+	}
+}
+
+func TestClient_Interpret_StructuredOutput(t *testing.T) {
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	var gotReq map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default(), openai.WithStructuredOutput(true))
+
+	if _, err := client.Interpret(context.Background(), testInput()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rf, ok := gotReq["response_format"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected response_format in request, got %v", gotReq)
+	}
+	if rf["type"] != "json_schema" {
+		t.Errorf("unexpected response_format type: %v", rf["type"])
+	}
+	js, _ := rf["json_schema"].(map[string]any)
+	schema, _ := js["schema"].(map[string]any)
+	if schema["type"] != "object" || js["strict"] != true {
+		t.Errorf("unexpected json_schema: %v", js)
+	}
+	cards, _ := schema["properties"].(map[string]any)["cards"].(map[string]any)
+	if _, ok := cards["minItems"]; ok {
+		t.Errorf("strict schema must not use minItems: %v", cards)
+	}
+}
+
+func TestClient_Interpret_GenerationParams(t *testing.T) {
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	var gotReq map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	temperature, modelTemperature, seed := 0.0, 0.7, int64(42)
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default(),
+		openai.WithParams(chat.Params{
+			Default:         ports.GenerationParams{Temperature: &temperature, MaxTokens: 800, Seed: &seed},
+			Models:          map[string]ports.GenerationParams{"other-model": {Temperature: &modelTemperature}},
+			LengthMaxTokens: map[string]int{"short": 300},
+		}))
+
+	if _, err := client.Interpret(context.Background(), testInput()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotReq["temperature"] != 0.0 || gotReq["max_tokens"] != 800.0 || gotReq["seed"] != 42.0 {
+		t.Errorf("unexpected params: %v", gotReq)
+	}
+	if _, ok := gotReq["top_p"]; ok {
+		t.Errorf("unset top_p was sent: %v", gotReq["top_p"])
+	}
+
+	// A requested length and reading seed override the configured ones.
+	in := testInput()
+	in.Length = "short"
+	readingSeed := int64(7)
+	in.Seed = &readingSeed
+	if _, err := client.Interpret(context.Background(), in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotReq["max_tokens"] != 300.0 || gotReq["seed"] != float64(chat.ProviderSeed(7)) {
+		t.Errorf("request overrides not applied: %v", gotReq)
+	}
+}
+
+func TestClient_Interpret_AzureStyle(t *testing.T) {
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/tarot/chat/completions" || r.URL.Query().Get("api-version") != "2024-06-01" {
+			t.Errorf("unexpected URL %s", r.URL)
+		}
+		if r.Header.Get("api-key") != "azure-key" || r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected auth headers: %v", r.Header)
+		}
+		if r.Header.Get("X-Tenant") != "acme" {
+			t.Errorf("extra header missing: %v", r.Header)
+		}
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "azure-key", srv.URL+"/openai/deployments/tarot?api-version=2024-06-01", "tarot", nil, slog.Default(),
+		openai.WithAuth("api-key", ""),
+		openai.WithHeaders(map[string]string{"X-Tenant": "acme"}))
+
+	if _, err := client.Interpret(context.Background(), testInput()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_Interpret_NoAPIKey(t *testing.T) {
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "ok", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("unexpected Authorization %q", got)
+		}
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "", srv.URL, "local-model", nil, slog.Default())
+
+	if _, err := client.Interpret(context.Background(), testInput()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_Interpret_FollowUpSendsHistory(t *testing.T) {
+	llmJSON, _ := json.Marshal(ports.InterpretOutput{Text: "For your job...", Cards: cardNotes(), Questions: []string{}, Style: "neutral"})
+
+	var roles []string
+	var last string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, m := range req.Messages {
+			roles = append(roles, m.Role)
+		}
+		last = req.Messages[len(req.Messages)-1].Content
+		resp := map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]any{"content": string(llmJSON)}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+
+	in := testInput()
+	in.History = []ports.Message{
+		{Role: ports.RoleUser, Content: in.Question},
+		{Role: ports.RoleAssistant, Content: `{"text":"The road opens."}`},
+	}
+	in.Question = "What does the Star mean for my job?"
+
+	out, err := client.Interpret(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Text != "For your job..." {
+		t.Errorf("unexpected text: %s", out.Text)
+	}
+	if got := strings.Join(roles, ","); got != "system,user,assistant,user" {
+		t.Errorf("unexpected roles: %s", got)
+	}
+	if !strings.Contains(last, "What does the Star mean for my job?") {
+		t.Errorf("follow-up question missing from last message:\n%s", last)
+	}
+}
+
+func TestClient_Check(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("unexpected Authorization %q", got)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+	if err := client.Check(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	status = http.StatusUnauthorized
+	if err := client.Check(context.Background()); err == nil {
+		t.Fatal("expected error for 401")
+	}
+}
+
+// OpenRouter lists models without a key, so its check asks about the key.
+func TestClient_CheckPath_RejectsBadKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/models":
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		case r.URL.Path == "/key" && r.Header.Get("Authorization") == "Bearer good":
+			_, _ = w.Write([]byte(`{"data":{"label":"taas"}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	check := func(key string) error {
+		return openai.NewClient(srv.Client(), key, srv.URL, "model", nil, slog.Default(), openai.WithCheckPath("/key")).Check(context.Background())
+	}
+	if err := check("good"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := check("revoked"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected a 401 for a revoked key, got %v", err)
+	}
+}
@@ -1,278 +0,0 @@
-package openrouter_test
-
-import (
-	"context"
-	"encoding/json"
-	"io"
-	"log/slog"
-	"net/http"
-	"strings"
-	"net/http/httptest"
-	"testing"
-
-	"github.com/randomtoy/taas-go/internal/adapters/llm/openrouter"
-	"github.com/randomtoy/taas-go/internal/ports"
-)
-
-func testInput() ports.InterpretInput {
-	return ports.InterpretInput{
-		DeckID:   "major_arcana",
-		Spread:   "three_card",
-		Question: "What lies ahead?",
-		Cards: []ports.CardInput{
-			{Name: "The Fool", Position: 1, Orientation: "upright", Keywords: []string{"beginnings"}, Short: "A fresh start."},
-			{Name: "The Magician", Position: 2, Orientation: "reversed", Keywords: []string{"willpower"}, Short: "Personal power."},
-			{Name: "The Star", Position: 3, Orientation: "upright", Keywords: []string{"hope"}, Short: "Renewed faith."},
-		},
-		Lang: "en",
-	}
-}
-
-func TestClient_Interpret_Success(t *testing.T) {
-	llmResp := ports.InterpretOutput{
-		Text:       "A thoughtful interpretation.",
-		Style:      "neutral",
-		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
-	}
-	llmJSON, _ := json.Marshal(llmResp)
-
-	var gotReq map[string]any
-
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Verify method and path.
-		if r.Method != http.MethodPost {
-			t.Errorf("expected POST, got %s", r.Method)
-		}
-		if r.URL.Path != "/chat/completions" {
-			t.Errorf("expected /chat/completions, got %s", r.URL.Path)
-		}
-		// Verify headers.
-		if r.Header.Get("Authorization") != "Bearer test-key" {
-			t.Errorf("bad auth header: %s", r.Header.Get("Authorization"))
-		}
-		if r.Header.Get("Content-Type") != "application/json" {
-			t.Errorf("bad content-type: %s", r.Header.Get("Content-Type"))
-		}
-
-		body, _ := io.ReadAll(r.Body)
-		_ = json.Unmarshal(body, &gotReq)
-
-		resp := map[string]any{
-			"choices": []map[string]any{
-				{"message": map[string]any{"content": string(llmJSON)}},
-			},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	}))
-	defer srv.Close()
-
-	client := openrouter.NewClient(
-		srv.Client(),
-		"test-key",
-		srv.URL,
-		"test-model",
-		nil,
-		slog.Default(),
-	)
-
-	out, err := client.Interpret(context.Background(), testInput())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	if out.Text != "A thoughtful interpretation." {
-		t.Errorf("unexpected text: %s", out.Text)
-	}
-	if out.Style != "neutral" {
-		t.Errorf("unexpected style: %s", out.Style)
-	}
-
-	// Verify the request body contains our model.
-	if gotReq["model"] != "test-model" {
-		t.Errorf("request model: %v", gotReq["model"])
-	}
-}
-
-func TestClient_Interpret_BadJSON_Retry_Success(t *testing.T) {
-	llmResp := ports.InterpretOutput{
-		Text:       "Retried interpretation.",
-		Style:      "neutral",
-		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
-	}
-	llmJSON, _ := json.Marshal(llmResp)
-
-	callCount := 0
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		callCount++
-		var content string
-		if callCount == 1 {
-			content = "this is not json at all"
-		} else {
-			content = string(llmJSON)
-		}
-
-		resp := map[string]any{
-			"choices": []map[string]any{
-				{"message": map[string]any{"content": content}},
-			},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	}))
-	defer srv.Close()
-
-	client := openrouter.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
-
-	out, err := client.Interpret(context.Background(), testInput())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if callCount != 2 {
-		t.Errorf("expected 2 calls (original + retry), got %d", callCount)
-	}
-	if out.Text != "Retried interpretation." {
-		t.Errorf("unexpected text: %s", out.Text)
-	}
-}
-
-func TestClient_Interpret_BadJSON_Retry_Failure(t *testing.T) {
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		resp := map[string]any{
-			"choices": []map[string]any{
-				{"message": map[string]any{"content": "still not json"}},
-			},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	}))
-	defer srv.Close()
-
-	client := openrouter.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
-
-	_, err := client.Interpret(context.Background(), testInput())
-	if err == nil {
-		t.Fatal("expected error for double-bad JSON, got nil")
-	}
-}
-
-func TestClient_Interpret_FallbackModel(t *testing.T) {
-	llmResp := ports.InterpretOutput{
-		Text:       "Fallback interpretation.",
-		Style:      "neutral",
-		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
-	}
-	llmJSON, _ := json.Marshal(llmResp)
-
-	var modelsRequested []string
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		body, _ := io.ReadAll(r.Body)
-		var req map[string]any
-		_ = json.Unmarshal(body, &req)
-		model := req["model"].(string)
-		modelsRequested = append(modelsRequested, model)
-
-		if model == "primary-model" {
-			w.WriteHeader(http.StatusTooManyRequests)
-			_, _ = w.Write([]byte(`{"error":"rate limited"}`))
-			return
-		}
-
-		resp := map[string]any{
-			"choices": []map[string]any{
-				{"message": map[string]any{"content": string(llmJSON)}},
-			},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	}))
-	defer srv.Close()
-
-	client := openrouter.NewClient(
-		srv.Client(), "key", srv.URL, "primary-model",
-		[]string{"fallback-model"}, slog.Default(),
-	)
-
-	out, err := client.Interpret(context.Background(), testInput())
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if len(modelsRequested) != 2 {
-		t.Errorf("expected 2 model attempts, got %d: %v", len(modelsRequested), modelsRequested)
-	}
-	if modelsRequested[0] != "primary-model" {
-		t.Errorf("first attempt should be primary-model, got %s", modelsRequested[0])
-	}
-	if modelsRequested[1] != "fallback-model" {
-		t.Errorf("second attempt should be fallback-model, got %s", modelsRequested[1])
-	}
-	if out.Text != "Fallback interpretation." {
-		t.Errorf("unexpected text: %s", out.Text)
-	}
-	if out.Model != "fallback-model" {
-		t.Errorf("expected model=fallback-model, got %s", out.Model)
-	}
-}
-
-func TestClient_Interpret_LangInPrompt(t *testing.T) {
-	llmResp := ports.InterpretOutput{
-		Text:       "Thoughtful interpretation in Russian.",
-		Style:      "neutral",
-		Disclaimer: "For reflection/entertainment; not medical/legal/financial advice.",
-	}
-	llmJSON, _ := json.Marshal(llmResp)
-
-	var gotMessages []map[string]any
-
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		body, _ := io.ReadAll(r.Body)
-		var req map[string]any
-		_ = json.Unmarshal(body, &req)
-		msgs, _ := req["messages"].([]any)
-		for _, m := range msgs {
-			gotMessages = append(gotMessages, m.(map[string]any))
-		}
-
-		resp := map[string]any{
-			"choices": []map[string]any{
-				{"message": map[string]any{"content": string(llmJSON)}},
-			},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	}))
-	defer srv.Close()
-
-	client := openrouter.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
-
-	in := testInput()
-	in.Lang = "ru"
-	_, err := client.Interpret(context.Background(), in)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	// Verify system prompt contains Russian language instruction.
-	if len(gotMessages) == 0 {
-		t.Fatal("no messages captured")
-	}
-	systemContent, _ := gotMessages[0]["content"].(string)
-	if !strings.Contains(systemContent, "Russian") {
-		t.Errorf("system prompt should mention Russian, got: %s", systemContent)
-	}
-}
-
-func TestClient_Interpret_UpstreamError(t *testing.T) {
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = w.Write([]byte(`{"error":"boom"}`))
-	}))
-	defer srv.Close()
-
-	client := openrouter.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
-
-	_, err := client.Interpret(context.Background(), testInput())
-	if err == nil {
-		t.Fatal("expected error for upstream 500, got nil")
-	}
-}
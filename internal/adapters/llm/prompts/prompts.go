@@ -0,0 +1,312 @@
+// Package prompts renders the LLM prompts from text/template files. The
+// default template set is embedded; a directory can override any of its
+// files so prompt authors can iterate without a code change.
+package prompts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// Template file names making up a set.
+const (
+	systemFile  = "system.tmpl"
+	userFile    = "user.tmpl"
+	retryFile   = "retry.tmpl"
+	followFile  = "followup.tmpl"
+	clarifyFile = "clarifier.tmpl"
+	schemaFile  = "schema.tmpl"
+	versionFile = "VERSION"
+)
+
+// templateFiles lists every file in a set: the prompts, the shared schema
+// and one persona fragment per registered style.
+func templateFiles() []string {
+	files := []string{systemFile, userFile, retryFile, followFile, clarifyFile, schemaFile}
+	for _, p := range domain.Personas() {
+		files = append(files, styleFile(p.Style))
+	}
+	return files
+}
+
+func styleFile(style domain.Style) string {
+	return "style_" + string(style) + ".tmpl"
+}
+
+var funcs = template.FuncMap{
+	"join":     strings.Join,
+	"userText": UserText,
+}
+
+// delimiterPattern matches markup a question could use to break out of its
+// delimited section or impersonate another chat role.
+var delimiterPattern = regexp.MustCompile(`(?i)</?\s*question\s*>|<\|[a-z_]+\|>|\[/?inst\]|<<\s*/?sys\s*>>|` + "```")
+
+// UserText neutralises user-supplied text before it is placed in a prompt:
+// it drops control characters and removes delimiter tags, code fences and
+// chat-template role markers, so the text cannot close its section.
+func UserText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = delimiterPattern.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
+
+// Set is a parsed, versioned collection of prompt templates.
+type Set struct {
+	version string
+	tmpl    *template.Template
+}
+
+// Default returns the embedded template set.
+func Default() *Set {
+	s, err := load(nil)
+	if err != nil {
+		panic(fmt.Sprintf("prompts: embedded templates are invalid: %v", err))
+	}
+	return s
+}
+
+// Load returns a set whose files come from dir where present and from the
+// embedded defaults otherwise. The version is read from dir/VERSION; if that
+// is missing it is derived from the template contents.
+func Load(dir string) (*Set, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("prompt directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("prompt directory %s is not a directory", dir)
+	}
+	return load(os.DirFS(dir))
+}
+
+func load(override fs.FS) (*Set, error) {
+	tmpl := template.New("prompts").Funcs(funcs).Option("missingkey=error")
+	hash := sha256.New()
+	overridden := false
+
+	for _, name := range templateFiles() {
+		raw, fromOverride, err := readFile(override, name)
+		if err != nil {
+			return nil, err
+		}
+		overridden = overridden || fromOverride
+		if _, err := tmpl.New(name).Parse(string(raw)); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		hash.Write(raw)
+	}
+
+	raw, fromOverride, err := readFile(override, versionFile)
+	if err != nil {
+		return nil, err
+	}
+	version := strings.TrimSpace(string(raw))
+	if overridden && !fromOverride {
+		version = "custom-" + hex.EncodeToString(hash.Sum(nil))[:12]
+	}
+	if version == "" {
+		return nil, fmt.Errorf("%s is empty", versionFile)
+	}
+
+	return &Set{version: version, tmpl: tmpl}, nil
+}
+
+// readFile prefers override and falls back to the embedded file.
+func readFile(override fs.FS, name string) ([]byte, bool, error) {
+	if override != nil {
+		raw, err := fs.ReadFile(override, name)
+		if err == nil {
+			return raw, true, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, false, fmt.Errorf("read %s: %w", name, err)
+		}
+	}
+	raw, err := templateFS.ReadFile("templates/" + name)
+	if err != nil {
+		return nil, false, fmt.Errorf("read embedded %s: %w", name, err)
+	}
+	return raw, false, nil
+}
+
+// Version identifies the template set; it is reported with each reading.
+func (s *Set) Version() string {
+	return s.version
+}
+
+// System renders the system prompt for the requested language and style.
+func (s *Set) System(in ports.InterpretInput) (string, error) {
+	persona, err := domain.LookupPersona(in.Style)
+	if err != nil {
+		return "", err
+	}
+	fragment, err := s.render(styleFile(persona.Style), nil)
+	if err != nil {
+		return "", err
+	}
+	return s.render(systemFile, systemData{
+		Lang:      in.Lang,
+		LangName:  langName(in.Lang),
+		Style:     string(persona.Style),
+		Persona:   fragment,
+		MaxWords:  domain.Length(in.Length).Words(persona.MaxWords),
+		Length:    in.Length,
+		Guidance:  in.Guidance,
+		Positions: positions(in),
+	})
+}
+
+// User renders the user prompt describing the spread and question.
+func (s *Set) User(in ports.InterpretInput) (string, error) {
+	return s.render(userFile, in)
+}
+
+// Conversation renders the chat turns that follow the system prompt. A
+// fresh reading is a single user prompt. With history, the first turn is
+// the user prompt for the original question, earlier answers are replayed
+// as assistant turns, and every later question gets the follow-up prompt.
+// The last turn asks about in.Question, or about the clarifiers in
+// in.Cards when in.ClarifiesPosition is set.
+func (s *Set) Conversation(in ports.InterpretInput) ([]ports.Message, error) {
+	// Earlier turns are about the spread, not the clarifiers.
+	spread := in
+	if in.ClarifiesPosition > 0 {
+		spread.Cards = in.Context
+	}
+
+	msgs := make([]ports.Message, 0, len(in.History)+1)
+	for i, m := range in.History {
+		if m.Role != ports.RoleUser {
+			msgs = append(msgs, m)
+			continue
+		}
+		turn := spread
+		turn.Question = m.Content
+		name := followFile
+		if i == 0 {
+			name = userFile
+		}
+		content, err := s.render(name, turn)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, ports.Message{Role: ports.RoleUser, Content: content})
+	}
+
+	name := userFile
+	switch {
+	case in.ClarifiesPosition > 0:
+		name = clarifyFile
+	case len(in.History) > 0:
+		name = followFile
+	}
+	last, err := s.render(name, in)
+	if err != nil {
+		return nil, err
+	}
+	return append(msgs, ports.Message{Role: ports.RoleUser, Content: last}), nil
+}
+
+// Retry renders the repair prompt sent after a response failed validation
+// for the given problems.
+func (s *Set) Retry(in ports.InterpretInput, previous string, problems []string) (string, error) {
+	persona, err := domain.LookupPersona(in.Style)
+	if err != nil {
+		return "", err
+	}
+	return s.render(retryFile, retryData{
+		Previous:  previous,
+		Problems:  problems,
+		Style:     string(persona.Style),
+		Positions: positions(in),
+	})
+}
+
+// systemData, retryData: the schema template also reads Style and Positions.
+type systemData struct {
+	Lang      string
+	LangName  string // empty for English, which needs no instruction
+	Style     string
+	Persona   string   // rendered style fragment
+	MaxWords  int      // the persona's limit, scaled by the requested length
+	Length    string   // short, medium, long or empty
+	Guidance  []string // extra care instructions, e.g. from safety guardrails
+	Positions []int
+}
+
+type retryData struct {
+	Previous  string
+	Problems  []string
+	Style     string
+	Positions []int
+}
+
+func positions(in ports.InterpretInput) []int {
+	out := make([]int, len(in.Cards))
+	for i, c := range in.Cards {
+		out[i] = c.Position
+	}
+	return out
+}
+
+func (s *Set) render(name string, data any) (string, error) {
+	var b bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&b, name, data); err != nil {
+		return "", fmt.Errorf("render %s (version %s): %w", name, s.version, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// langNames maps common BCP 47 codes to human-readable language names.
+var langNames = map[string]string{
+	"en": "English",
+	"ru": "Russian",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"zh": "Chinese",
+	"ar": "Arabic",
+	"hi": "Hindi",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"pl": "Polish",
+}
+
+func langName(lang string) string {
+	if lang == "" || lang == "en" {
+		return ""
+	}
+	if name, ok := langNames[lang]; ok {
+		return name
+	}
+	return lang
+}
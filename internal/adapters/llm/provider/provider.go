@@ -0,0 +1,71 @@
+// Package provider builds the LLM client for a configured provider, so
+// every command that talks to an LLM sets clients up the same way.
+package provider
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/anthropic"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/chat"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/openai"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/prompts"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/replay"
+	"github.com/randomtoy/taas-go/internal/config"
+	"github.com/randomtoy/taas-go/internal/health"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Client is an LLM adapter whose upstream can be health checked.
+type Client interface {
+	ports.Interpreter
+	health.Checker
+}
+
+// New returns a client for provider (openrouter, openai, anthropic or
+// replay) using the provider settings in cfg. OpenRouter is an
+// OpenAI-compatible API with its own key and base URL. With replay.record
+// set, the interpretations of LLM clients are recorded for replay.
+func New(cfg config.Config, provider, model string, fallbackModels []string, promptSet *prompts.Set, logger *slog.Logger) Client {
+	if provider == "replay" {
+		return replay.NewInterpreter(cfg.ReplayDir)
+	}
+	client := newLLM(cfg, provider, model, fallbackModels, promptSet, logger)
+	if cfg.ReplayRecord {
+		return recording{replay.NewRecorder(client, cfg.ReplayDir, logger), client}
+	}
+	return client
+}
+
+// recording records the interpretations of an LLM client and checks its
+// upstream.
+type recording struct {
+	*replay.Recorder
+	health.Checker
+}
+
+func newLLM(cfg config.Config, provider, model string, fallbackModels []string, promptSet *prompts.Set, logger *slog.Logger) Client {
+	httpClient := &http.Client{Timeout: cfg.LLMTimeout}
+	params := chat.Params{Default: cfg.LLMParams, Models: cfg.LLMModelParams, LengthMaxTokens: cfg.LLMLengthMaxTokens}
+	if provider == "anthropic" {
+		return anthropic.NewClient(httpClient, cfg.AnthropicAPIKey, cfg.AnthropicBaseURL, model, fallbackModels, logger,
+			anthropic.WithPrompts(promptSet),
+			anthropic.WithParams(params),
+			anthropic.WithVersion(cfg.AnthropicVersion),
+		)
+	}
+	opts := []openai.Option{
+		openai.WithPrompts(promptSet),
+		openai.WithStructuredOutput(cfg.LLMStructuredOutput),
+		openai.WithParams(params),
+	}
+	if provider == "openai" {
+		opts = append(opts,
+			openai.WithAuth(cfg.OpenAIAuthHeader, cfg.OpenAIAuthScheme),
+			openai.WithHeaders(cfg.OpenAIHeaders),
+		)
+		return openai.NewClient(httpClient, cfg.OpenAIAPIKey, cfg.OpenAIBaseURL, model, fallbackModels, logger, opts...)
+	}
+	opts = append(opts, openai.WithCheckPath("/key"))
+	return openai.NewClient(httpClient, cfg.OpenRouterAPIKey, cfg.OpenRouterBaseURL, model, fallbackModels, logger, opts...)
+}
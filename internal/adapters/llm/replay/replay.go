@@ -0,0 +1,223 @@
+// Package replay records interpretations to fixture files and serves them
+// back without an LLM, for end-to-end tests and offline demos. Fixtures
+// are keyed by a hash of the normalized input, so a replayed reading is
+// found again whatever the client key, seed or card texts.
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// ErrNoFixture is returned when no fixture was recorded for an input.
+var ErrNoFixture = errors.New("no recorded interpretation for this input")
+
+// Input is the part of an interpretation input that shapes the answer.
+// The client key and seed are left out, and cards are reduced to what the
+// deck cannot derive from their name.
+type Input struct {
+	DeckID            string    `json:"deck_id"`
+	Spread            string    `json:"spread"`
+	Question          string    `json:"question"`
+	Cards             []Card    `json:"cards"`
+	Lang              string    `json:"lang"`
+	Style             string    `json:"style"`
+	Length            string    `json:"length,omitempty"`
+	Guidance          []string  `json:"guidance,omitempty"`
+	ClarifiesPosition int       `json:"clarifies_position,omitempty"`
+	Context           []Card    `json:"context,omitempty"`
+	History           []Message `json:"history,omitempty"`
+}
+
+// Card is a drawn card in an Input.
+type Card struct {
+	Name        string `json:"name"`
+	Position    int    `json:"position"`
+	Orientation string `json:"orientation"`
+}
+
+// Message is an earlier turn in an Input.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Normalize reduces in to an Input. Whitespace in the question is
+// collapsed, the language is lower-cased and an empty style becomes the
+// default one.
+func Normalize(in ports.InterpretInput) Input {
+	out := Input{
+		DeckID:            in.DeckID,
+		Spread:            in.Spread,
+		Question:          strings.Join(strings.Fields(in.Question), " "),
+		Cards:             cards(in.Cards),
+		Lang:              strings.ToLower(strings.TrimSpace(in.Lang)),
+		Style:             in.Style,
+		Length:            strings.ToLower(in.Length),
+		Guidance:          in.Guidance,
+		ClarifiesPosition: in.ClarifiesPosition,
+		Context:           cards(in.Context),
+	}
+	if out.Style == "" {
+		out.Style = string(domain.DefaultStyle)
+	}
+	for _, m := range in.History {
+		out.History = append(out.History, Message{Role: m.Role, Content: m.Content})
+	}
+	return out
+}
+
+func cards(in []ports.CardInput) []Card {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]Card, len(in))
+	for i, c := range in {
+		out[i] = Card{Name: c.Name, Position: c.Position, Orientation: c.Orientation}
+	}
+	return out
+}
+
+// Key is the fixture key of in: the first 16 bytes of the SHA-256 of its
+// normalized form, in hex.
+func Key(in ports.InterpretInput) string {
+	raw, _ := json.Marshal(Normalize(in)) // plain strings and ints cannot fail
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Fixture is one recorded interpretation, stored as <key>.json. The input
+// is kept so fixtures can be reviewed.
+type Fixture struct {
+	Key           string                `json:"key"`
+	RecordedAt    time.Time             `json:"recorded_at"`
+	Input         Input                 `json:"input"`
+	Model         string                `json:"model"`
+	PromptVersion string                `json:"prompt_version,omitempty"`
+	Usage         Usage                 `json:"usage"`
+	Output        ports.InterpretOutput `json:"output"`
+}
+
+// Usage is the recorded token usage.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Recorder is a ports.Interpreter that saves every interpretation of the
+// interpreter it wraps to a fixture in its directory. Failed
+// interpretations are not recorded, and a fixture that cannot be written
+// is logged without failing the reading.
+type Recorder struct {
+	next   ports.Interpreter
+	dir    string
+	logger *slog.Logger
+}
+
+func NewRecorder(next ports.Interpreter, dir string, logger *slog.Logger) *Recorder {
+	return &Recorder{next: next, dir: dir, logger: logger}
+}
+
+func (r *Recorder) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	out, err := r.next.Interpret(ctx, in)
+	if err != nil {
+		return out, err
+	}
+	key := Key(in)
+	f := Fixture{
+		Key:           key,
+		RecordedAt:    time.Now().UTC(),
+		Input:         Normalize(in),
+		Model:         out.Model,
+		PromptVersion: out.PromptVersion,
+		Usage:         Usage(out.Usage),
+		Output:        out,
+	}
+	if err := r.write(f); err != nil {
+		r.logger.Warn("failed to record interpretation", "key", key, "error", err)
+	} else {
+		r.logger.Debug("interpretation recorded", "key", key)
+	}
+	return out, nil
+}
+
+// write replaces the fixture atomically, so a concurrent replay never
+// reads half a file.
+func (r *Recorder) write(f Fixture) error {
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(r.dir, f.Key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(raw, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(r.dir, f.Key+".json"))
+}
+
+// Interpreter implements ports.Interpreter by serving the fixtures in a
+// directory. Fixtures are read on every call, so recordings made while it
+// runs are served at once.
+type Interpreter struct {
+	dir string
+}
+
+func NewInterpreter(dir string) *Interpreter {
+	return &Interpreter{dir: dir}
+}
+
+// Interpret returns the recorded interpretation of in. It stands in for
+// an LLM, so errors wrap domain.ErrUpstreamLLM, and ErrNoFixture when
+// nothing was recorded. The recorded usage is not reported, since replaying
+// spends no tokens and must not count towards budgets.
+func (i *Interpreter) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	key := Key(in)
+	raw, err := os.ReadFile(filepath.Join(i.dir, key+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		err = ErrNoFixture
+	}
+	if err != nil {
+		return ports.InterpretOutput{}, fmt.Errorf("%w: replay %s: %w", domain.ErrUpstreamLLM, key, err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return ports.InterpretOutput{}, fmt.Errorf("%w: replay %s: decode fixture: %w", domain.ErrUpstreamLLM, key, err)
+	}
+	out := f.Output
+	out.Model = f.Model
+	out.PromptVersion = f.PromptVersion
+	return out, nil
+}
+
+// Check reports whether the fixture directory can be read.
+func (i *Interpreter) Check(context.Context) error {
+	if _, err := os.ReadDir(i.dir); err != nil {
+		return fmt.Errorf("replay fixtures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/adapters/decks"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/replay"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/template"
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/budget"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// fakeLLM answers like an LLM adapter, or fails with err.
+type fakeLLM struct {
+	err error
+}
+
+func (f *fakeLLM) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	if f.err != nil {
+		return ports.InterpretOutput{}, f.err
+	}
+	return ports.InterpretOutput{
+		Text:          "The Star brings hope about " + in.Question,
+		Summary:       "Hope.",
+		Theme:         "renewal",
+		Cards:         []ports.CardInterpretation{{Position: 1, Text: "Hope returns."}},
+		Questions:     []string{"What do you hope for?"},
+		Style:         "neutral",
+		Disclaimer:    "For reflection.",
+		Model:         "qwen/qwen3-4b:free",
+		PromptVersion: "v8",
+		Usage:         ports.Usage{PromptTokens: 300, CompletionTokens: 120, TotalTokens: 420},
+	}, nil
+}
+
+func input() ports.InterpretInput {
+	return ports.InterpretInput{
+		DeckID:   "major_arcana",
+		Spread:   "generic",
+		Question: "What about my new job?",
+		Cards:    []ports.CardInput{{Name: "The Star", Position: 1, Orientation: "upright", Keywords: []string{"hope"}}},
+		Lang:     "en",
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := replay.Key(input())
+
+	same := input()
+	same.Question = "  What about my   new job? "
+	same.Lang = "EN"
+	same.Style = "neutral"
+	same.ClientKey = "key-1"
+	seed := int64(7)
+	same.Seed = &seed
+	same.Cards[0].Keywords = nil
+	if replay.Key(same) != base {
+		t.Error("normalization does not make equivalent inputs share a key")
+	}
+
+	for name, change := range map[string]func(*ports.InterpretInput){
+		"orientation": func(in *ports.InterpretInput) { in.Cards[0].Orientation = "reversed" },
+		"question":    func(in *ports.InterpretInput) { in.Question = "What about love?" },
+		"style":       func(in *ports.InterpretInput) { in.Style = "poetic" },
+		"history": func(in *ports.InterpretInput) {
+			in.History = []ports.Message{{Role: ports.RoleUser, Content: "Before?"}}
+		},
+	} {
+		in := input()
+		change(&in)
+		if replay.Key(in) == base {
+			t.Errorf("%s does not change the key", name)
+		}
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	llm := &fakeLLM{}
+	recorded, err := replay.NewRecorder(llm, dir, logger).Interpret(context.Background(), input())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, replay.Key(input())+".json")); err != nil {
+		t.Fatalf("fixture not written: %v", err)
+	}
+
+	r := replay.NewInterpreter(dir)
+	if err := r.Check(context.Background()); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+	in := input()
+	in.Question = "What about my new job? "
+	got, err := r.Interpret(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := recorded
+	want.Usage = ports.Usage{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed %+v, recorded %+v", got, recorded)
+	}
+
+	in.Question = "Something else?"
+	if _, err := r.Interpret(context.Background(), in); !errors.Is(err, replay.ErrNoFixture) || !errors.Is(err, domain.ErrUpstreamLLM) {
+		t.Errorf("expected ErrNoFixture as an upstream failure, got %v", err)
+	}
+}
+
+func TestReplay_NotCharged(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := replay.NewRecorder(&fakeLLM{}, dir, logger).Interpret(context.Background(), input()); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := budget.NewTracker(budget.Limits{GlobalDaily: budget.Limit{Tokens: 1000}})
+	guard := budget.NewGuard(tracker, replay.NewInterpreter(dir), nil, logger)
+	in := input()
+	in.ClientKey = "key-1"
+	for range 3 {
+		if _, err := guard.Interpret(context.Background(), in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snap := tracker.Snapshot()
+	if snap.Global.Daily.Tokens != 0 || snap.Global.Hourly.Tokens != 0 {
+		t.Errorf("replays were charged: %+v", snap.Global)
+	}
+	for id, u := range snap.Keys {
+		if u.Daily.Tokens != 0 {
+			t.Errorf("replays were charged to key %s: %+v", id, u)
+		}
+	}
+}
+
+func TestRecorder_SkipsFailures(t *testing.T) {
+	dir := t.TempDir()
+	upstream := errors.New("upstream down")
+	if _, err := replay.NewRecorder(&fakeLLM{err: upstream}, dir, logger).Interpret(context.Background(), input()); !errors.Is(err, upstream) {
+		t.Fatalf("expected the upstream error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("failed interpretation recorded: %v", entries)
+	}
+}
+
+func TestRecorder_WriteFailureKeepsReading(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := replay.NewRecorder(&fakeLLM{}, file, logger).Interpret(context.Background(), input())
+	if err != nil || out.Text == "" {
+		t.Errorf("reading failed with the fixture write: %v", err)
+	}
+	if err := replay.NewInterpreter(file).Check(context.Background()); err == nil {
+		t.Error("Check passed without a fixture directory")
+	}
+}
+
+// A seeded reading recorded once replays end to end with the same cards
+// and interpretation.
+func TestReplay_SeededReading(t *testing.T) {
+	dir := t.TempDir()
+	seed := int64(42)
+	req := app.ReadSpreadRequest{Question: "Where am I heading?", NumCards: 3, DeckID: "major_arcana", SpreadType: "generic", Seed: &seed}
+
+	record := app.NewTarotService(decks.NewEmbeddedStore(), replay.NewRecorder(template.NewInterpreter(), dir, logger), nil, "")
+	want, err := record.ReadSpread(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	play := app.NewTarotService(decks.NewEmbeddedStore(), replay.NewInterpreter(dir), nil, "")
+	got, err := play.ReadSpread(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Cards, want.Cards) || !reflect.DeepEqual(got.Interpretation, want.Interpretation) {
+		t.Errorf("replayed reading differs:\n%+v\n%+v", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// ModelName is reported as the model for interpretations produced here.
+const ModelName = "template"
+
+const disclaimer = "For reflection/entertainment; not medical/legal/financial advice."
+
+// Interpreter implements ports.Interpreter without calling an LLM. It
+// assembles a short reading from each card's keywords and meaning, which
+// keeps the service answering when the LLM is unavailable or unaffordable.
+// The text is always English regardless of the requested language.
+type Interpreter struct{}
+
+func NewInterpreter() *Interpreter {
+	return &Interpreter{}
+}
+
+func (i *Interpreter) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	cards := make([]ports.CardInterpretation, len(in.Cards))
+	var b strings.Builder
+	if in.Question != "" {
+		fmt.Fprintf(&b, "Reflecting on %q:\n\n", in.Question)
+	}
+	for i, card := range in.Cards {
+		cards[i] = ports.CardInterpretation{Position: card.Position, Text: cardText(card)}
+		fmt.Fprintf(&b, "Position %d, %s (%s): %s\n", card.Position, card.Name, card.Orientation, cards[i].Text)
+	}
+	b.WriteString("\nTake what resonates and leave the rest; the cards invite reflection rather than predict outcomes.")
+
+	names := make([]string, len(in.Cards))
+	for i, card := range in.Cards {
+		names[i] = card.Name
+	}
+
+	return ports.InterpretOutput{
+		Text:    b.String(),
+		Summary: fmt.Sprintf("A reading of %s.", strings.Join(names, ", ")),
+		Theme:   theme(in.Cards),
+		Cards:   cards,
+		Questions: []string{
+			"Which of these cards feels most relevant to you right now?",
+			"What would change if you took one small step in that direction?",
+		},
+		Style:      "neutral",
+		Disclaimer: disclaimer,
+		Model:      ModelName,
+	}, nil
+}
+
+func cardText(card ports.CardInput) string {
+	if len(card.Keywords) == 0 {
+		return card.Short
+	}
+	verb := "Consider"
+	if card.Orientation == "reversed" {
+		verb = "Notice where something is blocked around"
+	}
+	return fmt.Sprintf("%s %s %s.", card.Short, verb, strings.Join(card.Keywords, ", "))
+}
+
+// theme uses the leading keyword of the first card, which anchors the spread.
+func theme(cards []ports.CardInput) string {
+	if len(cards) == 0 || len(cards[0].Keywords) == 0 {
+		return "reflection"
+	}
+	return cards[0].Keywords[0]
+}
@@ -0,0 +1,134 @@
+// Package readings stores readings so follow-up questions can be asked
+// about the same cards.
+package readings
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// MemoryStore keeps readings in process memory. Readings expire ttl after
+// their last turn; once maxEntries is reached the least recently active
+// reading is evicted. Contents are lost on restart.
+type MemoryStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*entry
+	order      *list.List // of *entry, least recently active first
+	now        func() time.Time
+}
+
+type entry struct {
+	reading ports.Reading
+	touched time.Time
+	elem    *list.Element
+}
+
+func NewMemoryStore(ttl time.Duration, maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*entry),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (s *MemoryStore) Save(_ context.Context, r ports.Reading) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	if e, exists := s.entries[r.ID]; exists {
+		s.remove(e)
+	} else if s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		s.remove(s.order.Front().Value.(*entry))
+	}
+	e := &entry{reading: clone(r), touched: now}
+	e.elem = s.order.PushBack(e)
+	s.entries[r.ID] = e
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (ports.Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.live(id, s.now())
+	if !ok {
+		return ports.Reading{}, domain.ErrReadingNotFound
+	}
+	return clone(e.reading), nil
+}
+
+func (s *MemoryStore) AppendTurn(_ context.Context, id string, t ports.Turn, maxFollowUps int) (ports.Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e, ok := s.live(id, now)
+	if !ok {
+		return ports.Reading{}, domain.ErrReadingNotFound
+	}
+	if maxFollowUps > 0 && e.reading.FollowUps() >= maxFollowUps {
+		return ports.Reading{}, domain.ErrFollowUpLimit
+	}
+	if err := domain.CheckClarifiers(e.reading.Cards, e.reading.Clarifiers, t.Clarifiers); err != nil {
+		return ports.Reading{}, err
+	}
+	e.reading.Thread = append(e.reading.Thread, t)
+	e.reading.Clarifiers = append(e.reading.Clarifiers, t.Clarifiers...)
+	e.touched = now
+	s.order.MoveToBack(e.elem)
+	return clone(e.reading), nil
+}
+
+// live returns the entry for id unless it has expired.
+func (s *MemoryStore) live(id string, now time.Time) (*entry, bool) {
+	e, ok := s.entries[id]
+	if !ok {
+		return nil, false
+	}
+	if s.expired(e, now) {
+		s.remove(e)
+		return nil, false
+	}
+	return e, true
+}
+
+func (s *MemoryStore) expired(e *entry, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(e.touched) >= s.ttl
+}
+
+// prune drops expired readings. They are the least recently active, so
+// only the front of the order is looked at.
+func (s *MemoryStore) prune(now time.Time) {
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		e := front.Value.(*entry)
+		if !s.expired(e, now) {
+			return
+		}
+		s.remove(e)
+	}
+}
+
+func (s *MemoryStore) remove(e *entry) {
+	s.order.Remove(e.elem)
+	delete(s.entries, e.reading.ID)
+}
+
+// clone copies the slices callers could otherwise mutate under the lock.
+func clone(r ports.Reading) ports.Reading {
+	r.Cards = slices.Clone(r.Cards)
+	r.Clarifiers = slices.Clone(r.Clarifiers)
+	r.Thread = slices.Clone(r.Thread)
+	return r
+}
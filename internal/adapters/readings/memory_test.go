@@ -0,0 +1,132 @@
+package readings
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func TestMemoryStore_SaveGetAppend(t *testing.T) {
+	s := NewMemoryStore(time.Hour, 10)
+	ctx := context.Background()
+
+	if err := s.Save(ctx, ports.Reading{ID: "r1", Thread: []ports.Turn{{Question: "first"}}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.AppendTurn(ctx, "r1", ports.Turn{Question: "second"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Thread) != 2 || got.Thread[1].Question != "second" {
+		t.Fatalf("unexpected thread: %+v", got.Thread)
+	}
+
+	got.Thread[0].Question = "mutated"
+	again, _ := s.Get(ctx, "r1")
+	if again.Thread[0].Question != "first" {
+		t.Error("store returned a thread aliasing its own storage")
+	}
+
+	if _, err := s.Get(ctx, "missing"); !errors.Is(err, domain.ErrReadingNotFound) {
+		t.Errorf("expected ErrReadingNotFound, got %v", err)
+	}
+}
+
+func TestMemoryStore_FollowUpLimit(t *testing.T) {
+	s := NewMemoryStore(time.Hour, 10)
+	ctx := context.Background()
+
+	_ = s.Save(ctx, ports.Reading{ID: "r1", Thread: []ports.Turn{{Question: "first"}}})
+	if _, err := s.AppendTurn(ctx, "r1", ports.Turn{Question: "second"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AppendTurn(ctx, "r1", ports.Turn{Question: "third"}, 1); !errors.Is(err, domain.ErrFollowUpLimit) {
+		t.Errorf("expected ErrFollowUpLimit, got %v", err)
+	}
+	if r, _ := s.Get(ctx, "r1"); len(r.Thread) != 2 {
+		t.Errorf("turn appended past the limit: %+v", r.Thread)
+	}
+}
+
+func TestMemoryStore_RejectsClarifiersOnTable(t *testing.T) {
+	s := NewMemoryStore(time.Hour, 10)
+	ctx := context.Background()
+	card := domain.DrawnCard{Card: domain.Card{ID: "star"}, Position: 2}
+
+	_ = s.Save(ctx, ports.Reading{ID: "r1", Cards: []domain.DrawnCard{{Card: domain.Card{ID: "sun"}, Position: 1}}})
+	turn := ports.Turn{Clarifiers: []domain.Clarifier{{DrawnCard: card, ForPosition: 1}}}
+	if _, err := s.AppendTurn(ctx, "r1", turn, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AppendTurn(ctx, "r1", turn, 0); !errors.Is(err, domain.ErrTableChanged) {
+		t.Errorf("expected ErrTableChanged, got %v", err)
+	}
+	if r, _ := s.Get(ctx, "r1"); len(r.Clarifiers) != 1 || len(r.Thread) != 1 {
+		t.Errorf("conflicting clarifiers appended: %+v", r)
+	}
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(time.Hour, 10)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = s.Save(ctx, ports.Reading{ID: "r1"})
+	now = now.Add(50 * time.Minute)
+	if _, err := s.AppendTurn(ctx, "r1", ports.Turn{}, 0); err != nil {
+		t.Fatalf("reading expired too early: %v", err)
+	}
+
+	// A follow-up extends the reading's life.
+	now = now.Add(50 * time.Minute)
+	if _, err := s.Get(ctx, "r1"); err != nil {
+		t.Fatalf("follow-up did not extend ttl: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := s.Get(ctx, "r1"); !errors.Is(err, domain.ErrReadingNotFound) {
+		t.Errorf("expected expired reading, got %v", err)
+	}
+}
+
+func TestMemoryStore_EvictsLeastRecentlyActive(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(0, 2)
+	s.now = func() time.Time { now = now.Add(time.Second); return now }
+	ctx := context.Background()
+
+	_ = s.Save(ctx, ports.Reading{ID: "a"})
+	_ = s.Save(ctx, ports.Reading{ID: "b"})
+	_, _ = s.AppendTurn(ctx, "a", ports.Turn{}, 0)
+	_ = s.Save(ctx, ports.Reading{ID: "c"})
+
+	if _, err := s.Get(ctx, "b"); !errors.Is(err, domain.ErrReadingNotFound) {
+		t.Errorf("expected b to be evicted, got %v", err)
+	}
+	for _, id := range []string{"a", "c"} {
+		if _, err := s.Get(ctx, id); err != nil {
+			t.Errorf("%s: %v", id, err)
+		}
+	}
+}
+
+func TestMemoryStore_ResaveKeepsOneEntry(t *testing.T) {
+	s := NewMemoryStore(0, 2)
+	ctx := context.Background()
+
+	_ = s.Save(ctx, ports.Reading{ID: "a"})
+	_ = s.Save(ctx, ports.Reading{ID: "a", Lang: "ru"})
+	_ = s.Save(ctx, ports.Reading{ID: "b"})
+
+	if r, err := s.Get(ctx, "a"); err != nil || r.Lang != "ru" {
+		t.Errorf("a = %+v, %v", r, err)
+	}
+	if len(s.entries) != 2 || s.order.Len() != 2 {
+		t.Errorf("%d entries, %d in order", len(s.entries), s.order.Len())
+	}
+}
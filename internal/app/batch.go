@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchConcurrency applies when WithBatchConcurrency is not used.
+const defaultBatchConcurrency = 4
+
+// WithBatchConcurrency limits how many readings of a batch are
+// interpreted at the same time.
+func WithBatchConcurrency(n int) Option {
+	return func(s *TarotService) {
+		if n > 0 {
+			s.batchConcurrency = n
+		}
+	}
+}
+
+// BatchResult is the outcome of the request at Index in a batch.
+type BatchResult struct {
+	Index    int
+	Response ReadSpreadResponse
+	Err      error
+}
+
+// ReadSpreadBatch runs ReadSpread for every request, at most
+// batchConcurrency at a time, and calls emit with each result as soon as
+// it completes. emit is called from the calling goroutine, never
+// concurrently. Requests not yet started when ctx ends fail with ctx's
+// error.
+func (s *TarotService) ReadSpreadBatch(ctx context.Context, reqs []ReadSpreadRequest, emit func(BatchResult)) {
+	results := make(chan BatchResult)
+	sem := make(chan struct{}, s.batchConcurrency)
+
+	var wg sync.WaitGroup
+	go func() {
+		defer close(results)
+		for i, req := range reqs {
+			if ctx.Err() == nil {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				results <- BatchResult{Index: i, Err: err}
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				resp, err := s.ReadSpread(ctx, req)
+				results <- BatchResult{Index: i, Response: resp, Err: err}
+			}()
+		}
+		wg.Wait()
+	}()
+
+	for r := range results {
+		emit(r)
+	}
+}
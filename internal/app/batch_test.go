@@ -0,0 +1,81 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// slowInterpreter tracks how many calls run at once.
+type slowInterpreter struct {
+	running, peak atomic.Int32
+}
+
+func (s *slowInterpreter) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		p := s.peak.Load()
+		if n <= p || s.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return ports.InterpretOutput{Text: in.Question}, nil
+}
+
+func TestReadSpreadBatch_PartialSuccessWithBoundedConcurrency(t *testing.T) {
+	interp := &slowInterpreter{}
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, interp, fixedRNG{val: 0}, "test-model",
+		app.WithBatchConcurrency(2))
+
+	reqs := make([]app.ReadSpreadRequest, 8)
+	for i := range reqs {
+		reqs[i] = app.ReadSpreadRequest{Question: "q", NumCards: 3, DeckID: "major_arcana"}
+	}
+	reqs[5].NumCards = 40
+
+	got := make(map[int]error)
+	svc.ReadSpreadBatch(context.Background(), reqs, func(r app.BatchResult) {
+		if _, dup := got[r.Index]; dup {
+			t.Errorf("index %d reported twice", r.Index)
+		}
+		got[r.Index] = r.Err
+	})
+
+	if len(got) != len(reqs) {
+		t.Fatalf("expected %d results, got %d", len(reqs), len(got))
+	}
+	for i, err := range got {
+		if i == 5 {
+			if !errors.Is(err, domain.ErrInvalidN) {
+				t.Errorf("item 5: expected ErrInvalidN, got %v", err)
+			}
+		} else if err != nil {
+			t.Errorf("item %d: unexpected error %v", i, err)
+		}
+	}
+	if p := interp.peak.Load(); p > 2 {
+		t.Errorf("concurrency limit exceeded: %d calls at once", p)
+	}
+}
+
+func TestReadSpreadBatch_Cancelled(t *testing.T) {
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, &slowInterpreter{}, fixedRNG{val: 0}, "test-model",
+		app.WithBatchConcurrency(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reqs := []app.ReadSpreadRequest{{NumCards: 1, DeckID: "major_arcana"}, {NumCards: 1, DeckID: "major_arcana"}}
+	svc.ReadSpreadBatch(ctx, reqs, func(r app.BatchResult) {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("item %d: expected context.Canceled, got %v", r.Index, r.Err)
+		}
+	})
+}
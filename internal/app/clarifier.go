@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// ClarifyRequest draws clarifiers for one position of a stored reading.
+type ClarifyRequest struct {
+	ReadingID string
+	Position  int    // spread position the clarifiers are attached to
+	Count     int    // number of clarifiers, 1 to domain.MaxClarifiers
+	Question  string // optional
+	APIKey    string // optional; used to attribute LLM spend
+}
+
+// ClarifyResponse holds the clarifiers, their interpretation and the
+// updated reading.
+type ClarifyResponse struct {
+	Reading        ports.Reading
+	Clarifiers     []domain.Clarifier
+	Interpretation ports.InterpretOutput
+	Model          string
+	PromptVersion  string
+	LatencyMS      int64
+}
+
+// Clarify draws clarifiers from the reading's deck, skipping every card
+// already on the table, and interprets them in the context of the spread
+// and the conversation so far. If a concurrent draw for the same reading
+// was saved first, the clarifiers are not saved and domain.ErrTableChanged
+// is returned.
+func (s *TarotService) Clarify(ctx context.Context, req ClarifyRequest) (ClarifyResponse, error) {
+	reading, err := s.openReading(ctx, req.ReadingID)
+	if err != nil {
+		return ClarifyResponse{}, err
+	}
+
+	deck, err := s.deckStore.GetDeck(ctx, reading.DeckID)
+	if err != nil {
+		return ClarifyResponse{}, fmt.Errorf("get deck: %w", err)
+	}
+
+	clarifiers, err := domain.DrawClarifiers(deck, reading.Cards, reading.Clarifiers, req.Position, req.Count, s.rng)
+	if err != nil {
+		return ClarifyResponse{}, fmt.Errorf("draw clarifiers: %w", err)
+	}
+
+	history, err := threadHistory(reading.Thread)
+	if err != nil {
+		return ClarifyResponse{}, err
+	}
+
+	drawn := make([]domain.DrawnCard, len(clarifiers))
+	for i, c := range clarifiers {
+		drawn[i] = c.DrawnCard
+	}
+
+	llmInput := ports.InterpretInput{
+		DeckID:            reading.DeckID,
+		Spread:            string(reading.Spread),
+		Question:          req.Question,
+		Cards:             toCardInputs(drawn),
+		Lang:              reading.Lang,
+		Style:             reading.Style,
+		Length:            reading.Length,
+		ClientKey:         req.APIKey,
+		ClarifiesPosition: req.Position,
+		Context:           toCardInputs(reading.Cards),
+		History:           history,
+	}
+
+	start := time.Now()
+	interpretation, err := s.interpreter.Interpret(ctx, llmInput)
+	latency := time.Since(start).Milliseconds()
+
+	if err != nil {
+		return ClarifyResponse{}, fmt.Errorf("interpret: %w", err)
+	}
+
+	model := interpretationModel(interpretation.Model, s.model)
+	reading, err = s.readings.AppendTurn(ctx, reading.ID, ports.Turn{
+		Question:       req.Question,
+		Clarifiers:     clarifiers,
+		Interpretation: interpretation,
+		Model:          model,
+		CreatedAt:      s.now(),
+	}, s.maxFollowUps)
+	if err != nil {
+		return ClarifyResponse{}, fmt.Errorf("save clarifiers: %w", err)
+	}
+
+	return ClarifyResponse{
+		Reading:        reading,
+		Clarifiers:     clarifiers,
+		Interpretation: interpretation,
+		Model:          model,
+		PromptVersion:  interpretation.PromptVersion,
+		LatencyMS:      latency,
+	}, nil
+}
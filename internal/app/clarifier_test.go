@@ -0,0 +1,112 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func TestClarify_DrawsUnusedCardsForPosition(t *testing.T) {
+	interp := &recordingInterpreter{}
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, interp, fixedRNG{val: 0}, "test-model",
+		app.WithReadings(&memReadings{}, 5))
+	ctx := context.Background()
+
+	first, err := svc.ReadSpread(ctx, app.ReadSpreadRequest{NumCards: 3, DeckID: "major_arcana"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := svc.Clarify(ctx, app.ClarifyRequest{ReadingID: first.ReadingID, Position: 2, Count: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := svc.Clarify(ctx, app.ClarifyRequest{ReadingID: first.ReadingID, Position: 2, Count: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	used := make(map[string]bool)
+	for _, c := range first.Cards {
+		used[c.ID] = true
+	}
+	for _, c := range append(resp.Clarifiers, second.Clarifiers...) {
+		if used[c.ID] {
+			t.Errorf("clarifier %s was already on the table", c.ID)
+		}
+		used[c.ID] = true
+		if c.ForPosition != 2 {
+			t.Errorf("clarifier attached to position %d, want 2", c.ForPosition)
+		}
+	}
+	if got := second.Clarifiers[0].Position; got != 6 {
+		t.Errorf("clarifier positions should continue the table numbering, got %d", got)
+	}
+	if len(second.Reading.Clarifiers) != 3 || len(second.Reading.Thread) != 3 {
+		t.Errorf("reading not updated: %d clarifiers, %d turns", len(second.Reading.Clarifiers), len(second.Reading.Thread))
+	}
+
+	in := interp.last
+	if in.ClarifiesPosition != 2 || len(in.Context) != 3 || len(in.Cards) != 1 {
+		t.Errorf("unexpected interpreter input: position %d, %d context, %d cards", in.ClarifiesPosition, len(in.Context), len(in.Cards))
+	}
+	if len(in.History) != 4 || in.History[2].Role != ports.RoleUser {
+		t.Fatalf("unexpected history: %+v", in.History)
+	}
+}
+
+func TestClarify_InvalidPosition(t *testing.T) {
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, &recordingInterpreter{}, fixedRNG{val: 0}, "test-model",
+		app.WithReadings(&memReadings{}, 5))
+	ctx := context.Background()
+
+	first, _ := svc.ReadSpread(ctx, app.ReadSpreadRequest{NumCards: 3, DeckID: "major_arcana"})
+	_, err := svc.Clarify(ctx, app.ClarifyRequest{ReadingID: first.ReadingID, Position: 9, Count: 1})
+	if !errors.Is(err, domain.ErrInvalidPosition) {
+		t.Errorf("expected ErrInvalidPosition, got %v", err)
+	}
+}
+
+func TestClarify_ConcurrentDrawsNeverShareCards(t *testing.T) {
+	interp := &gatedInterpreter{started: make(chan struct{}), release: make(chan struct{})}
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, interp, fixedRNG{val: 0}, "test-model",
+		app.WithReadings(&memReadings{}, 5))
+	ctx := context.Background()
+
+	go func() { <-interp.started; interp.release <- struct{}{} }()
+	first, err := svc.ReadSpread(ctx, app.ReadSpreadRequest{NumCards: 3, DeckID: "major_arcana"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// With a fixed RNG both calls draw the same card from the same table.
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := svc.Clarify(ctx, app.ClarifyRequest{ReadingID: first.ReadingID, Position: 1, Count: 1})
+			errs <- err
+		}()
+	}
+	<-interp.started
+	<-interp.started
+	close(interp.release)
+
+	var changed int
+	for range 2 {
+		if err := <-errs; errors.Is(err, domain.ErrTableChanged) {
+			changed++
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if changed != 1 {
+		t.Errorf("%d of 2 concurrent draws were rejected", changed)
+	}
+	if r, _ := svc.GetReading(ctx, first.ReadingID); len(r.Clarifiers) != 1 {
+		t.Errorf("reading has %d clarifiers", len(r.Clarifiers))
+	}
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// FollowUpRequest asks another question about an existing reading.
+type FollowUpRequest struct {
+	ReadingID string
+	Question  string
+	APIKey    string // optional; used to attribute LLM spend
+}
+
+// FollowUpResponse is the answer together with the updated reading.
+type FollowUpResponse struct {
+	Reading        ports.Reading
+	Interpretation ports.InterpretOutput
+	Model          string
+	PromptVersion  string
+	LatencyMS      int64
+}
+
+// GetReading returns a stored reading and its conversation thread.
+func (s *TarotService) GetReading(ctx context.Context, id string) (ports.Reading, error) {
+	if s.readings == nil {
+		return ports.Reading{}, domain.ErrReadingNotFound
+	}
+	r, err := s.readings.Get(ctx, id)
+	if err != nil {
+		return ports.Reading{}, fmt.Errorf("get reading: %w", err)
+	}
+	return r, nil
+}
+
+// FollowUp interprets a new question against the cards already drawn for
+// a reading, sending the earlier turns as conversation history, and
+// appends the answer to the reading's thread.
+func (s *TarotService) FollowUp(ctx context.Context, req FollowUpRequest) (FollowUpResponse, error) {
+	if strings.TrimSpace(req.Question) == "" {
+		return FollowUpResponse{}, domain.ErrQuestionRequired
+	}
+
+	reading, err := s.openReading(ctx, req.ReadingID)
+	if err != nil {
+		return FollowUpResponse{}, err
+	}
+
+	history, err := threadHistory(reading.Thread)
+	if err != nil {
+		return FollowUpResponse{}, err
+	}
+
+	llmInput := ports.InterpretInput{
+		DeckID:    reading.DeckID,
+		Spread:    string(reading.Spread),
+		Question:  req.Question,
+		Cards:     toCardInputs(reading.Cards),
+		Lang:      reading.Lang,
+		Style:     reading.Style,
+		Length:    reading.Length,
+		ClientKey: req.APIKey,
+		History:   history,
+	}
+
+	start := time.Now()
+	interpretation, err := s.interpreter.Interpret(ctx, llmInput)
+	latency := time.Since(start).Milliseconds()
+
+	if err != nil {
+		return FollowUpResponse{}, fmt.Errorf("interpret: %w", err)
+	}
+
+	model := interpretationModel(interpretation.Model, s.model)
+	reading, err = s.readings.AppendTurn(ctx, reading.ID, ports.Turn{
+		Question:       req.Question,
+		Interpretation: interpretation,
+		Model:          model,
+		CreatedAt:      s.now(),
+	}, s.maxFollowUps)
+	if err != nil {
+		return FollowUpResponse{}, fmt.Errorf("save follow-up: %w", err)
+	}
+
+	return FollowUpResponse{
+		Reading:        reading,
+		Interpretation: interpretation,
+		Model:          model,
+		PromptVersion:  interpretation.PromptVersion,
+		LatencyMS:      latency,
+	}, nil
+}
+
+// openReading returns a reading that can take another turn: follow-ups
+// and clarifiers both count towards the limit. It saves an LLM call on a
+// reading that is already full; AppendTurn enforces the limit again, since
+// concurrent turns may both pass this check.
+func (s *TarotService) openReading(ctx context.Context, id string) (ports.Reading, error) {
+	reading, err := s.GetReading(ctx, id)
+	if err != nil {
+		return ports.Reading{}, err
+	}
+	if s.maxFollowUps > 0 && reading.FollowUps() >= s.maxFollowUps {
+		return ports.Reading{}, domain.ErrFollowUpLimit
+	}
+	return reading, nil
+}
+
+// threadHistory replays each turn as the question asked and the
+// interpretation JSON the model produced for it.
+func threadHistory(thread []ports.Turn) ([]ports.Message, error) {
+	history := make([]ports.Message, 0, 2*len(thread))
+	for _, t := range thread {
+		answer, err := json.Marshal(t.Interpretation)
+		if err != nil {
+			return nil, fmt.Errorf("encode earlier interpretation: %w", err)
+		}
+		history = append(history,
+			ports.Message{Role: ports.RoleUser, Content: turnQuestion(t)},
+			ports.Message{Role: ports.RoleAssistant, Content: string(answer)},
+		)
+	}
+	return history, nil
+}
+
+// turnQuestion describes a turn for replay; clarifier turns name the cards
+// that were drawn, since those are not part of the spread prompt.
+func turnQuestion(t ports.Turn) string {
+	if len(t.Clarifiers) == 0 {
+		return t.Question
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Clarifiers drawn for position %d:", t.Clarifiers[0].ForPosition)
+	for i, c := range t.Clarifiers {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, " %s (%s) at position %d", c.Name, c.Orientation, c.Position)
+	}
+	b.WriteString(".")
+	if t.Question != "" {
+		b.WriteString(" " + t.Question)
+	}
+	return b.String()
+}
+
+func newReadingID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,175 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+type memReadings struct {
+	mu sync.Mutex
+	m  map[string]ports.Reading
+}
+
+func (s *memReadings) Save(_ context.Context, r ports.Reading) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[string]ports.Reading)
+	}
+	s.m[r.ID] = r
+	return nil
+}
+
+func (s *memReadings) Get(_ context.Context, id string) (ports.Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.m[id]
+	if !ok {
+		return ports.Reading{}, domain.ErrReadingNotFound
+	}
+	return r, nil
+}
+
+func (s *memReadings) AppendTurn(_ context.Context, id string, t ports.Turn, maxFollowUps int) (ports.Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.m[id]
+	if !ok {
+		return ports.Reading{}, domain.ErrReadingNotFound
+	}
+	if maxFollowUps > 0 && r.FollowUps() >= maxFollowUps {
+		return ports.Reading{}, domain.ErrFollowUpLimit
+	}
+	if err := domain.CheckClarifiers(r.Cards, r.Clarifiers, t.Clarifiers); err != nil {
+		return ports.Reading{}, err
+	}
+	r.Thread = append(r.Thread, t)
+	r.Clarifiers = append(r.Clarifiers, t.Clarifiers...)
+	s.m[id] = r
+	return r, nil
+}
+
+// recordingInterpreter remembers the last input it was given.
+type recordingInterpreter struct {
+	last ports.InterpretInput
+}
+
+func (r *recordingInterpreter) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	r.last = in
+	return ports.InterpretOutput{Text: "answer to " + in.Question, Style: "neutral"}, nil
+}
+
+func TestFollowUp_KeepsCardsAndHistory(t *testing.T) {
+	interp := &recordingInterpreter{}
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, interp, fixedRNG{val: 0}, "test-model",
+		app.WithReadings(&memReadings{}, 5))
+	ctx := context.Background()
+
+	first, err := svc.ReadSpread(ctx, app.ReadSpreadRequest{
+		Question: "What lies ahead?", NumCards: 3, DeckID: "major_arcana", Lang: "ru", Style: "warm", Length: "short",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.ReadingID == "" {
+		t.Fatal("expected a reading ID")
+	}
+
+	resp, err := svc.FollowUp(ctx, app.FollowUpRequest{ReadingID: first.ReadingID, Question: "And my job?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	in := interp.last
+	if len(in.Cards) != 3 || in.Cards[0].Name != first.Cards[0].Name {
+		t.Errorf("follow-up did not reuse the drawn cards: %+v", in.Cards)
+	}
+	if in.Lang != "ru" || in.Style != "warm" || in.Length != "short" {
+		t.Errorf("follow-up lost lang/style/length: %q %q %q", in.Lang, in.Style, in.Length)
+	}
+	if len(in.History) != 2 || in.History[0].Content != "What lies ahead?" || in.History[1].Role != ports.RoleAssistant {
+		t.Errorf("unexpected history: %+v", in.History)
+	}
+	if len(resp.Reading.Thread) != 2 || resp.Reading.Thread[1].Interpretation.Text != "answer to And my job?" {
+		t.Errorf("thread not updated: %+v", resp.Reading.Thread)
+	}
+}
+
+func TestFollowUp_Errors(t *testing.T) {
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, &recordingInterpreter{}, fixedRNG{val: 0}, "test-model",
+		app.WithReadings(&memReadings{}, 1))
+	ctx := context.Background()
+
+	if _, err := svc.FollowUp(ctx, app.FollowUpRequest{ReadingID: "nope", Question: "?"}); !errors.Is(err, domain.ErrReadingNotFound) {
+		t.Errorf("expected ErrReadingNotFound, got %v", err)
+	}
+
+	first, _ := svc.ReadSpread(ctx, app.ReadSpreadRequest{NumCards: 1, DeckID: "major_arcana"})
+	if _, err := svc.FollowUp(ctx, app.FollowUpRequest{ReadingID: first.ReadingID, Question: " "}); !errors.Is(err, domain.ErrQuestionRequired) {
+		t.Errorf("expected ErrQuestionRequired, got %v", err)
+	}
+	if _, err := svc.FollowUp(ctx, app.FollowUpRequest{ReadingID: first.ReadingID, Question: "one"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.FollowUp(ctx, app.FollowUpRequest{ReadingID: first.ReadingID, Question: "two"}); !errors.Is(err, domain.ErrFollowUpLimit) {
+		t.Errorf("expected ErrFollowUpLimit, got %v", err)
+	}
+}
+
+// gatedInterpreter holds every call until released, so calls overlap.
+type gatedInterpreter struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (g *gatedInterpreter) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	g.started <- struct{}{}
+	<-g.release
+	return ports.InterpretOutput{Text: "answer to " + in.Question, Style: "neutral"}, nil
+}
+
+func TestFollowUp_ConcurrentCallsKeepLimit(t *testing.T) {
+	interp := &gatedInterpreter{started: make(chan struct{}), release: make(chan struct{})}
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, interp, fixedRNG{val: 0}, "test-model",
+		app.WithReadings(&memReadings{}, 1))
+	ctx := context.Background()
+
+	go func() { <-interp.started; interp.release <- struct{}{} }()
+	first, err := svc.ReadSpread(ctx, app.ReadSpreadRequest{NumCards: 1, DeckID: "major_arcana"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	errs := make(chan error, 2)
+	for _, q := range []string{"one", "two"} {
+		go func() {
+			_, err := svc.FollowUp(ctx, app.FollowUpRequest{ReadingID: first.ReadingID, Question: q})
+			errs <- err
+		}()
+	}
+	// Both calls pass the early check before either is saved.
+	<-interp.started
+	<-interp.started
+	close(interp.release)
+
+	var limited int
+	for range 2 {
+		if err := <-errs; errors.Is(err, domain.ErrFollowUpLimit) {
+			limited++
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if limited != 1 {
+		t.Errorf("%d of 2 concurrent follow-ups hit the limit of 1", limited)
+	}
+	if r, _ := svc.GetReading(ctx, first.ReadingID); r.FollowUps() != 1 {
+		t.Errorf("reading has %d follow-ups", r.FollowUps())
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/randomtoy/taas-go/internal/domain"
@@ -16,15 +17,21 @@ type ReadSpreadRequest struct {
 	DeckID     string
 	SpreadType string
 	Lang       string
+	Style      string // persona name; empty selects domain.DefaultStyle
+	Length     string // short, medium or long; empty keeps the configured length
+	Seed       *int64 // optional; makes the draw repeatable and seeds the LLM where supported
+	APIKey     string // optional; used to attribute LLM spend
 }
 
 // ReadSpreadResponse is the application-level output.
 type ReadSpreadResponse struct {
+	ReadingID      string // empty when readings are not stored
 	SpreadType     domain.SpreadType
 	DeckID         string
 	Cards          []domain.DrawnCard
 	Interpretation ports.InterpretOutput
 	Model          string
+	PromptVersion  string
 	LatencyMS      int64
 }
 
@@ -34,18 +41,51 @@ type TarotService struct {
 	interpreter ports.Interpreter
 	rng         domain.RNG
 	model       string
+
+	readings         ports.ReadingStore
+	maxFollowUps     int
+	batchConcurrency int
+	now              func() time.Time
+}
+
+// Option customizes a TarotService.
+type Option func(*TarotService)
+
+// WithReadings stores every reading in store so follow-up questions can be
+// asked about it, allowing at most maxFollowUps per reading (0 = no limit).
+func WithReadings(store ports.ReadingStore, maxFollowUps int) Option {
+	return func(s *TarotService) {
+		s.readings = store
+		s.maxFollowUps = maxFollowUps
+	}
 }
 
-func NewTarotService(ds ports.DeckStore, interp ports.Interpreter, rng domain.RNG, model string) *TarotService {
-	return &TarotService{
+func NewTarotService(ds ports.DeckStore, interp ports.Interpreter, rng domain.RNG, model string, opts ...Option) *TarotService {
+	s := &TarotService{
 		deckStore:   ds,
 		interpreter: interp,
 		rng:         rng,
 		model:       model,
+
+		batchConcurrency: defaultBatchConcurrency,
+		now:              time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *TarotService) ReadSpread(ctx context.Context, req ReadSpreadRequest) (ReadSpreadResponse, error) {
+	persona, err := domain.LookupPersona(req.Style)
+	if err != nil {
+		return ReadSpreadResponse{}, fmt.Errorf("resolve style %q: %w", req.Style, err)
+	}
+	length, err := domain.ParseLength(req.Length)
+	if err != nil {
+		return ReadSpreadResponse{}, fmt.Errorf("resolve length %q: %w", req.Length, err)
+	}
+
 	deck, err := s.deckStore.GetDeck(ctx, req.DeckID)
 	if err != nil {
 		return ReadSpreadResponse{}, fmt.Errorf("get deck: %w", err)
@@ -53,17 +93,25 @@ func (s *TarotService) ReadSpread(ctx context.Context, req ReadSpreadRequest) (R
 
 	st := resolveSpreadType(req.SpreadType, req.NumCards)
 
-	spread, err := domain.GenerateSpread(deck, req.NumCards, st, s.rng)
+	rng := s.rng
+	if req.Seed != nil {
+		rng = seededRNG(*req.Seed)
+	}
+	spread, err := domain.GenerateSpread(deck, req.NumCards, st, rng)
 	if err != nil {
 		return ReadSpreadResponse{}, fmt.Errorf("generate spread: %w", err)
 	}
 
 	llmInput := ports.InterpretInput{
-		DeckID:   req.DeckID,
-		Spread:   string(st),
-		Question: req.Question,
-		Cards:    toCardInputs(spread.Cards),
-		Lang:     req.Lang,
+		DeckID:    req.DeckID,
+		Spread:    string(st),
+		Question:  req.Question,
+		Cards:     toCardInputs(spread.Cards),
+		Lang:      req.Lang,
+		Style:     string(persona.Style),
+		Length:    string(length),
+		ClientKey: req.APIKey,
+		Seed:      req.Seed,
 	}
 
 	start := time.Now()
@@ -74,16 +122,54 @@ func (s *TarotService) ReadSpread(ctx context.Context, req ReadSpreadRequest) (R
 		return ReadSpreadResponse{}, fmt.Errorf("interpret: %w", err)
 	}
 
+	model := interpretationModel(interpretation.Model, s.model)
+
+	var readingID string
+	if s.readings != nil {
+		readingID = newReadingID()
+		now := s.now()
+		err := s.readings.Save(ctx, ports.Reading{
+			ID:     readingID,
+			DeckID: req.DeckID,
+			Spread: st,
+			Lang:   req.Lang,
+			Style:  string(persona.Style),
+			Length: string(length),
+			Cards:  spread.Cards,
+			Thread: []ports.Turn{{
+				Question:       req.Question,
+				Interpretation: interpretation,
+				Model:          model,
+				CreatedAt:      now,
+			}},
+			CreatedAt: now,
+		})
+		if err != nil {
+			return ReadSpreadResponse{}, fmt.Errorf("save reading: %w", err)
+		}
+	}
+
 	return ReadSpreadResponse{
+		ReadingID:      readingID,
 		SpreadType:     st,
 		DeckID:         req.DeckID,
 		Cards:          spread.Cards,
 		Interpretation: interpretation,
-		Model:          interpretationModel(interpretation.Model, s.model),
+		Model:          model,
+		PromptVersion:  interpretation.PromptVersion,
 		LatencyMS:      latency,
 	}, nil
 }
 
+// seededRNG draws the same cards for the same seed.
+func seededRNG(seed int64) domain.RNG {
+	return pcgRNG{rand.New(rand.NewPCG(uint64(seed), 0))}
+}
+
+type pcgRNG struct{ r *rand.Rand }
+
+func (p pcgRNG) Intn(n int) int { return p.r.IntN(n) }
+
 func resolveSpreadType(raw string, n int) domain.SpreadType {
 	switch raw {
 	case "three_card":
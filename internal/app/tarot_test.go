@@ -2,6 +2,8 @@ package app_test
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/randomtoy/taas-go/internal/app"
@@ -105,3 +107,55 @@ func TestReadSpread_LLMFailure(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestReadSpread_UnknownStyle(t *testing.T) {
+	ds := &mockDeckStore{deck: testDeck()}
+	interp := &mockInterpreter{}
+	svc := app.NewTarotService(ds, interp, fixedRNG{val: 0}, "test-model")
+
+	_, err := svc.ReadSpread(context.Background(), app.ReadSpreadRequest{
+		NumCards: 3,
+		DeckID:   "major_arcana",
+		Style:    "grumpy",
+	})
+	if !errors.Is(err, domain.ErrUnknownStyle) {
+		t.Fatalf("expected ErrUnknownStyle, got %v", err)
+	}
+}
+
+func TestReadSpread_InvalidLength(t *testing.T) {
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, &mockInterpreter{}, fixedRNG{val: 0}, "test-model")
+
+	_, err := svc.ReadSpread(context.Background(), app.ReadSpreadRequest{
+		NumCards: 3,
+		DeckID:   "major_arcana",
+		Length:   "epic",
+	})
+	if !errors.Is(err, domain.ErrInvalidLength) {
+		t.Fatalf("expected ErrInvalidLength, got %v", err)
+	}
+}
+
+func TestReadSpread_SeedRepeatsDraw(t *testing.T) {
+	interp := &recordingInterpreter{}
+	// The service's own RNG is not used when a seed is given.
+	svc := app.NewTarotService(&mockDeckStore{deck: testDeck()}, interp, nil, "test-model")
+	seed := int64(42)
+	req := app.ReadSpreadRequest{NumCards: 5, DeckID: "major_arcana", Length: "long", Seed: &seed}
+
+	first, err := svc.ReadSpread(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interp.last.Seed == nil || *interp.last.Seed != 42 || interp.last.Length != "long" {
+		t.Errorf("seed or length not passed to the interpreter: %+v", interp.last)
+	}
+
+	second, err := svc.ReadSpread(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(first.Cards, second.Cards) {
+		t.Errorf("same seed drew different cards:\n%+v\n%+v", first.Cards, second.Cards)
+	}
+}
@@ -0,0 +1,85 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Action selects what the Guard does once a budget is exhausted.
+type Action string
+
+const (
+	// ActionReject fails the request with domain.ErrBudgetExceeded.
+	ActionReject Action = "reject"
+	// ActionDowngrade routes the request to a cheaper model.
+	ActionDowngrade Action = "downgrade"
+	// ActionTemplate answers with a non-LLM interpretation.
+	ActionTemplate Action = "template"
+)
+
+// ParseAction validates a configured action name.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionReject, ActionDowngrade, ActionTemplate:
+		return a, nil
+	default:
+		return "", fmt.Errorf("invalid budget action %q (want reject, downgrade or template)", s)
+	}
+}
+
+// Guard implements ports.Interpreter by reserving budget before each call
+// and settling it with the tokens the call spent, including those of
+// failed calls, rejected replies and repair retries. Once a budget is used
+// up, requests go to the degraded interpreter, or are rejected if it is
+// nil.
+type Guard struct {
+	tracker  *Tracker
+	primary  ports.Interpreter
+	degraded ports.Interpreter
+	logger   *slog.Logger
+}
+
+func NewGuard(tracker *Tracker, primary, degraded ports.Interpreter, logger *slog.Logger) *Guard {
+	return &Guard{
+		tracker:  tracker,
+		primary:  primary,
+		degraded: degraded,
+		logger:   logger,
+	}
+}
+
+func (g *Guard) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	reservation, err := g.tracker.Reserve(in.ClientKey)
+	if err != nil {
+		if g.degraded == nil {
+			g.logger.WarnContext(ctx, "rejecting request over budget", "error", err)
+			return ports.InterpretOutput{}, err
+		}
+		g.logger.WarnContext(ctx, "over budget, using degraded interpreter", "error", err)
+		out, err := g.degraded.Interpret(ctx, in)
+		g.record(in.ClientKey, out, err)
+		return out, err
+	}
+
+	out, err := g.primary.Interpret(ctx, in)
+	if err != nil {
+		g.tracker.Commit(reservation, ports.SpendOf(err))
+		return ports.InterpretOutput{}, err
+	}
+	g.tracker.Commit(reservation, out.TotalSpend())
+	return out, nil
+}
+
+// record adds the spend of a call made without a reservation.
+func (g *Guard) record(key string, out ports.InterpretOutput, err error) {
+	spend := out.TotalSpend()
+	if err != nil {
+		spend = ports.SpendOf(err)
+	}
+	for _, m := range spend {
+		g.tracker.Record(key, m.Model, m.Usage)
+	}
+}
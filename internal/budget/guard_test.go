@@ -0,0 +1,87 @@
+package budget_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/budget"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+type stubInterpreter struct {
+	model string
+	calls int
+}
+
+func (s *stubInterpreter) Interpret(_ context.Context, _ ports.InterpretInput) (ports.InterpretOutput, error) {
+	s.calls++
+	return ports.InterpretOutput{Text: "ok", Model: s.model, Usage: ports.Usage{TotalTokens: 50}}, nil
+}
+
+func TestGuard_Reject(t *testing.T) {
+	tr := budget.NewTracker(budget.Limits{KeyHourly: budget.Limit{Tokens: 50}})
+	primary := &stubInterpreter{model: "primary"}
+	g := budget.NewGuard(tr, primary, nil, slog.Default())
+
+	in := ports.InterpretInput{ClientKey: "k"}
+	if _, err := g.Interpret(context.Background(), in); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if _, err := g.Interpret(context.Background(), in); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary should be called once, got %d", primary.calls)
+	}
+}
+
+func TestGuard_Degrade(t *testing.T) {
+	tr := budget.NewTracker(budget.Limits{GlobalDaily: budget.Limit{Tokens: 50}})
+	primary := &stubInterpreter{model: "primary"}
+	cheap := &stubInterpreter{model: "cheap"}
+	g := budget.NewGuard(tr, primary, cheap, slog.Default())
+
+	if _, err := g.Interpret(context.Background(), ports.InterpretInput{}); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	out, err := g.Interpret(context.Background(), ports.InterpretInput{})
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if out.Model != "cheap" {
+		t.Errorf("expected degraded model, got %s", out.Model)
+	}
+	if got := tr.Snapshot().Global.Daily.Tokens; got != 100 {
+		t.Errorf("expected degraded usage to be recorded too, got %d tokens", got)
+	}
+}
+
+type failingInterpreter struct{}
+
+func (failingInterpreter) Interpret(context.Context, ports.InterpretInput) (ports.InterpretOutput, error) {
+	return ports.InterpretOutput{}, &ports.SpendError{
+		Spend: []ports.ModelUsage{{Model: "m", Usage: ports.Usage{TotalTokens: 80}}},
+		Err:   domain.ErrInvalidLLMJSON,
+	}
+}
+
+// Tokens spent on replies that never became an interpretation are charged.
+func TestGuard_RecordsSpendOfFailures(t *testing.T) {
+	tr := budget.NewTracker(budget.Limits{KeyHourly: budget.Limit{Tokens: 100}})
+	g := budget.NewGuard(tr, failingInterpreter{}, nil, slog.Default())
+
+	in := ports.InterpretInput{ClientKey: "k"}
+	if _, err := g.Interpret(context.Background(), in); !errors.Is(err, domain.ErrInvalidLLMJSON) {
+		t.Fatalf("expected the interpreter's error, got %v", err)
+	}
+	if got := tr.Snapshot().Keys[budget.KeyID("k")].Hourly.Tokens; got != 80 {
+		t.Errorf("recorded %d tokens, want 80", got)
+	}
+	g.Interpret(context.Background(), in)
+	if _, err := g.Interpret(context.Background(), in); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Errorf("expected failed calls to use up the budget, got %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package budget
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Limit caps consumption within one window. Zero fields mean unlimited.
+type Limit struct {
+	Tokens  int64   `json:"tokens,omitempty"`
+	CostUSD float64 `json:"cost_usd,omitempty"`
+}
+
+// Limits configures the tracker. Prices are USD per million tokens keyed by
+// model; models without a price accrue tokens but no cost.
+//
+// Keys are whatever the client sends and are not authenticated, so a
+// client can start a fresh key budget by changing its key. Key limits only
+// share spend fairly among well-behaved clients; the global limits are the
+// real cap.
+type Limits struct {
+	GlobalHourly Limit
+	GlobalDaily  Limit
+	KeyHourly    Limit
+	KeyDaily     Limit
+	Prices       map[string]float64
+}
+
+// Uncapped reports whether key limits are set without any global limit,
+// which leaves total spend unbounded.
+func (l Limits) Uncapped() bool {
+	set := func(limit Limit) bool { return limit != Limit{} }
+	return (set(l.KeyHourly) || set(l.KeyDaily)) && !set(l.GlobalHourly) && !set(l.GlobalDaily)
+}
+
+// Spend is the consumption recorded in a single window.
+type Spend struct {
+	WindowStart time.Time `json:"window_start"`
+	Tokens      int64     `json:"tokens"`
+	CostUSD     float64   `json:"cost_usd"`
+	Limit       Limit     `json:"limit"`
+}
+
+// Usage is the hourly and daily spend for one scope.
+type Usage struct {
+	Hourly Spend `json:"hourly"`
+	Daily  Spend `json:"daily"`
+}
+
+// Snapshot is a point-in-time view of all tracked spend. Keys are
+// fingerprints (see KeyID), never raw API keys.
+type Snapshot struct {
+	Global Usage            `json:"global"`
+	Keys   map[string]Usage `json:"keys"`
+}
+
+type window struct {
+	start   time.Time
+	tokens  int64
+	costUSD float64
+}
+
+// roll resets the window if start has moved on.
+func (w *window) roll(start time.Time) {
+	if !w.start.Equal(start) {
+		*w = window{start: start}
+	}
+}
+
+func (w *window) exceeds(l Limit) bool {
+	if l.Tokens > 0 && w.tokens >= l.Tokens {
+		return true
+	}
+	return l.CostUSD > 0 && w.costUSD >= l.CostUSD
+}
+
+type scope struct {
+	hourly window
+	daily  window
+}
+
+func (s *scope) roll(now time.Time) {
+	s.hourly.roll(now.Truncate(time.Hour))
+	s.daily.roll(now.Truncate(24 * time.Hour))
+}
+
+func (s *scope) add(tokens int64, cost float64) {
+	s.hourly.tokens += tokens
+	s.hourly.costUSD += cost
+	s.daily.tokens += tokens
+	s.daily.costUSD += cost
+}
+
+// sub takes a reservation back out of the windows it was added to; a
+// window that has rolled over since no longer holds it.
+func (s *scope) sub(r *Reservation) {
+	if s.hourly.start.Equal(r.hourly) {
+		s.hourly.tokens -= r.tokens
+		s.hourly.costUSD -= r.costUSD
+	}
+	if s.daily.start.Equal(r.daily) {
+		s.daily.tokens -= r.tokens
+		s.daily.costUSD -= r.costUSD
+	}
+}
+
+// defaultEstimate is the tokens reserved per call until one has been
+// recorded: about a three-card reading.
+const defaultEstimate = 1500
+
+// Tracker accounts token and cost consumption globally and per API key over
+// fixed hourly and daily UTC windows.
+type Tracker struct {
+	mu     sync.Mutex
+	limits Limits
+	now    func() time.Time
+	global scope
+	keys   map[string]*scope
+	pruned time.Time // hour keys were last pruned in
+
+	// Running averages of a call's spend, used as the reservation size.
+	avgTokens float64
+	avgCost   float64
+}
+
+func NewTracker(limits Limits) *Tracker {
+	return &Tracker{
+		limits:    limits,
+		now:       time.Now,
+		keys:      make(map[string]*scope),
+		avgTokens: defaultEstimate,
+	}
+}
+
+// Reservation is spend set aside for a call in progress.
+type Reservation struct {
+	key           string // fingerprint, empty without an API key
+	hourly, daily time.Time
+	tokens        int64
+	costUSD       float64
+}
+
+// Reserve checks the budgets like Check and, under the same lock, sets
+// aside the expected spend of one call (the running average of earlier
+// calls) globally and for key. Concurrent calls therefore cannot all pass
+// a budget that only has room for one. The reservation must be settled
+// with Commit.
+func (t *Tracker) Reserve(key string) (*Reservation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.checkLocked(key); err != nil {
+		return nil, err
+	}
+	now := t.now().UTC()
+	r := &Reservation{
+		hourly:  now.Truncate(time.Hour),
+		daily:   now.Truncate(24 * time.Hour),
+		tokens:  int64(t.avgTokens),
+		costUSD: t.avgCost,
+	}
+	t.global.add(r.tokens, r.costUSD)
+	if key != "" {
+		r.key = KeyID(key)
+		t.keyScope(r.key, now).add(r.tokens, r.costUSD)
+	}
+	return r, nil
+}
+
+// Commit replaces a reservation with the spend the call actually made,
+// which may be empty if it failed before reaching a model.
+func (t *Tracker) Commit(r *Reservation, spend []ports.ModelUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now().UTC()
+	t.global.roll(now)
+	t.global.sub(r)
+	if s, ok := t.keys[r.key]; ok && r.key != "" {
+		s.roll(now)
+		s.sub(r)
+	}
+
+	var tokens int64
+	var cost float64
+	for _, m := range spend {
+		mt, mc := t.price(m)
+		t.addLocked(r.key, mt, mc, now)
+		tokens += mt
+		cost += mc
+	}
+	if tokens > 0 {
+		const weight = 0.2
+		t.avgTokens += weight * (float64(tokens) - t.avgTokens)
+		t.avgCost += weight * (cost - t.avgCost)
+	}
+}
+
+// SetLimits replaces the limits and prices. Spend so far is kept and
+// counts against the new limits.
+func (t *Tracker) SetLimits(limits Limits) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits = limits
+}
+
+// Check returns an error wrapping domain.ErrBudgetExceeded if the global
+// budget or the budget for key has been used up.
+func (t *Tracker) Check(key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.checkLocked(key)
+}
+
+func (t *Tracker) checkLocked(key string) error {
+	now := t.now().UTC()
+	t.global.roll(now)
+	if t.global.hourly.exceeds(t.limits.GlobalHourly) {
+		return fmt.Errorf("%w: global hourly limit", domain.ErrBudgetExceeded)
+	}
+	if t.global.daily.exceeds(t.limits.GlobalDaily) {
+		return fmt.Errorf("%w: global daily limit", domain.ErrBudgetExceeded)
+	}
+
+	if key == "" {
+		return nil
+	}
+	s, ok := t.keys[KeyID(key)]
+	if !ok {
+		return nil
+	}
+	s.roll(now)
+	if s.hourly.exceeds(t.limits.KeyHourly) {
+		return fmt.Errorf("%w: hourly limit for API key", domain.ErrBudgetExceeded)
+	}
+	if s.daily.exceeds(t.limits.KeyDaily) {
+		return fmt.Errorf("%w: daily limit for API key", domain.ErrBudgetExceeded)
+	}
+	return nil
+}
+
+// Record adds usage by model to the global scope and to key, if set.
+func (t *Tracker) Record(key, model string, u ports.Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := ""
+	if key != "" {
+		id = KeyID(key)
+	}
+	tokens, cost := t.price(ports.ModelUsage{Model: model, Usage: u})
+	t.addLocked(id, tokens, cost, t.now().UTC())
+}
+
+// price returns the tokens of m and what they cost.
+func (t *Tracker) price(m ports.ModelUsage) (int64, float64) {
+	tokens := int64(m.Usage.TotalTokens)
+	if tokens == 0 {
+		tokens = int64(m.Usage.PromptTokens + m.Usage.CompletionTokens)
+	}
+	return tokens, float64(tokens) * t.limits.Prices[m.Model] / 1e6
+}
+
+// addLocked adds spend globally and to the key with fingerprint id, if set.
+func (t *Tracker) addLocked(id string, tokens int64, cost float64, now time.Time) {
+	t.global.roll(now)
+	t.global.add(tokens, cost)
+	if id != "" {
+		t.keyScope(id, now).add(tokens, cost)
+	}
+}
+
+// keyScope returns the rolled scope for fingerprint id, creating it.
+func (t *Tracker) keyScope(id string, now time.Time) *scope {
+	t.pruneLocked(now)
+	s, ok := t.keys[id]
+	if !ok {
+		s = &scope{}
+		t.keys[id] = s
+	}
+	s.roll(now)
+	return s
+}
+
+// pruneLocked drops, once an hour, the keys with nothing spent or reserved
+// in the current day, so only keys in use today are kept however many
+// distinct X-Api-Key values are sent.
+func (t *Tracker) pruneLocked(now time.Time) {
+	hour := now.Truncate(time.Hour)
+	if hour.Equal(t.pruned) {
+		return
+	}
+	t.pruned = hour
+	for id, s := range t.keys {
+		s.roll(now)
+		if s.daily.tokens == 0 && s.daily.costUSD == 0 {
+			delete(t.keys, id)
+		}
+	}
+}
+
+// Snapshot returns current spend. Keys whose daily window has expired are
+// dropped from the tracker as a side effect.
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now().UTC()
+	t.global.roll(now)
+
+	snap := Snapshot{
+		Global: t.usage(&t.global, t.limits.GlobalHourly, t.limits.GlobalDaily),
+		Keys:   make(map[string]Usage, len(t.keys)),
+	}
+	for id, s := range t.keys {
+		s.roll(now)
+		if s.daily.tokens == 0 {
+			delete(t.keys, id)
+			continue
+		}
+		snap.Keys[id] = t.usage(s, t.limits.KeyHourly, t.limits.KeyDaily)
+	}
+	return snap
+}
+
+func (t *Tracker) usage(s *scope, hourly, daily Limit) Usage {
+	return Usage{
+		Hourly: Spend{WindowStart: s.hourly.start, Tokens: s.hourly.tokens, CostUSD: s.hourly.costUSD, Limit: hourly},
+		Daily:  Spend{WindowStart: s.daily.start, Tokens: s.daily.tokens, CostUSD: s.daily.costUSD, Limit: daily},
+	}
+}
+
+// KeyID returns a short stable fingerprint of an API key so spend can be
+// reported and logged without exposing the key itself.
+func KeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
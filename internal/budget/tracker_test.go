@@ -0,0 +1,187 @@
+package budget
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func TestTracker_GlobalTokenLimit(t *testing.T) {
+	tr := NewTracker(Limits{GlobalHourly: Limit{Tokens: 100}})
+
+	if err := tr.Check(""); err != nil {
+		t.Fatalf("unexpected error before spend: %v", err)
+	}
+	tr.Record("", "m", ports.Usage{TotalTokens: 60})
+	if err := tr.Check(""); err != nil {
+		t.Fatalf("unexpected error under limit: %v", err)
+	}
+	tr.Record("", "m", ports.Usage{TotalTokens: 40})
+	if err := tr.Check(""); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+}
+
+func TestTracker_SetLimitsKeepsSpend(t *testing.T) {
+	tr := NewTracker(Limits{GlobalHourly: Limit{Tokens: 100}})
+	tr.Record("", "m", ports.Usage{TotalTokens: 60})
+
+	tr.SetLimits(Limits{GlobalHourly: Limit{Tokens: 50}})
+	if err := tr.Check(""); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded under the lowered limit, got %v", err)
+	}
+}
+
+func TestTracker_PerKeyCostLimit(t *testing.T) {
+	tr := NewTracker(Limits{
+		KeyDaily: Limit{CostUSD: 1},
+		Prices:   map[string]float64{"pricey": 2}, // $2 per 1M tokens
+	})
+
+	tr.Record("alice", "pricey", ports.Usage{TotalTokens: 500_000})
+	if err := tr.Check("alice"); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("expected alice over budget, got %v", err)
+	}
+	if err := tr.Check("bob"); err != nil {
+		t.Fatalf("bob should be unaffected, got %v", err)
+	}
+
+	snap := tr.Snapshot()
+	u, ok := snap.Keys[KeyID("alice")]
+	if !ok {
+		t.Fatalf("snapshot missing alice: %+v", snap.Keys)
+	}
+	if u.Daily.CostUSD != 1 || u.Daily.Tokens != 500_000 {
+		t.Errorf("unexpected daily spend: %+v", u.Daily)
+	}
+	if _, ok := snap.Keys["alice"]; ok {
+		t.Error("snapshot must not expose raw API keys")
+	}
+}
+
+func TestTracker_WindowRollover(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+	tr := NewTracker(Limits{GlobalHourly: Limit{Tokens: 10}, GlobalDaily: Limit{Tokens: 25}})
+	tr.now = func() time.Time { return now }
+
+	tr.Record("", "m", ports.Usage{TotalTokens: 10})
+	if err := tr.Check(""); err == nil {
+		t.Fatal("expected hourly limit to be hit")
+	}
+
+	now = now.Add(time.Hour)
+	if err := tr.Check(""); err != nil {
+		t.Fatalf("hourly window should have reset: %v", err)
+	}
+	tr.Record("", "m", ports.Usage{TotalTokens: 9})
+	now = now.Add(time.Hour)
+	tr.Record("", "m", ports.Usage{TotalTokens: 9})
+	if err := tr.Check(""); err == nil {
+		t.Fatal("expected daily limit to be hit")
+	}
+
+	now = now.Add(24 * time.Hour)
+	if err := tr.Check(""); err != nil {
+		t.Fatalf("daily window should have reset: %v", err)
+	}
+}
+
+func TestTracker_ReserveIsAtomic(t *testing.T) {
+	tr := NewTracker(Limits{GlobalHourly: Limit{Tokens: 1000}})
+	var wg sync.WaitGroup
+	var granted atomic.Int32
+	for range 20 {
+		wg.Go(func() {
+			if _, err := tr.Reserve("k"); err == nil {
+				granted.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	if granted.Load() != 1 {
+		t.Errorf("%d concurrent calls passed a budget with room for one", granted.Load())
+	}
+}
+
+func TestTracker_CommitReplacesReservation(t *testing.T) {
+	tr := NewTracker(Limits{KeyHourly: Limit{Tokens: 1000}, Prices: map[string]float64{"a": 2}})
+	r, err := tr.Reserve("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Reserve("k"); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("expected the reservation to use up the budget, got %v", err)
+	}
+
+	tr.Commit(r, []ports.ModelUsage{
+		{Model: "a", Usage: ports.Usage{TotalTokens: 200}},
+		{Model: "b", Usage: ports.Usage{PromptTokens: 60, CompletionTokens: 40}},
+	})
+	snap := tr.Snapshot()
+	if got := snap.Keys[KeyID("k")].Hourly; got.Tokens != 300 || got.CostUSD != 0.0004 {
+		t.Errorf("key spend = %+v, want 300 tokens, $0.0004", got)
+	}
+	if snap.Global.Hourly.Tokens != 300 {
+		t.Errorf("global spend = %d tokens, want 300", snap.Global.Hourly.Tokens)
+	}
+	if _, err := tr.Reserve("k"); err != nil {
+		t.Errorf("budget not released by Commit: %v", err)
+	}
+}
+
+func TestTracker_CommitAfterRollover(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 59, 0, 0, time.UTC)
+	tr := NewTracker(Limits{})
+	tr.now = func() time.Time { return now }
+	r, _ := tr.Reserve("")
+
+	now = now.Add(2 * time.Minute)
+	tr.Commit(r, []ports.ModelUsage{{Model: "m", Usage: ports.Usage{TotalTokens: 100}}})
+	snap := tr.Snapshot()
+	if snap.Global.Hourly.Tokens != 100 || snap.Global.Daily.Tokens != 100 {
+		t.Errorf("spend after rollover = %+v", snap.Global)
+	}
+}
+
+func TestTracker_PrunesIdleKeys(t *testing.T) {
+	tr := NewTracker(Limits{KeyDaily: Limit{Tokens: 1000}})
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	tr.Record("old", "m", ports.Usage{TotalTokens: 10})
+	now = now.Add(24 * time.Hour)
+	tr.Record("active", "m", ports.Usage{TotalTokens: 10})
+	now = now.Add(time.Hour)
+	r, _ := tr.Reserve("new")
+	tr.Commit(r, []ports.ModelUsage{{Model: "m", Usage: ports.Usage{TotalTokens: 10}}})
+
+	if _, ok := tr.keys[KeyID("old")]; ok {
+		t.Error("key idle since yesterday kept without an admin read")
+	}
+	for _, key := range []string{"active", "new"} {
+		if _, ok := tr.keys[KeyID(key)]; !ok {
+			t.Errorf("key %s with spend today dropped", key)
+		}
+	}
+}
+
+func TestLimits_Uncapped(t *testing.T) {
+	for _, tc := range []struct {
+		limits Limits
+		want   bool
+	}{
+		{Limits{}, false},
+		{Limits{KeyDaily: Limit{Tokens: 10}}, true},
+		{Limits{KeyDaily: Limit{Tokens: 10}, GlobalHourly: Limit{CostUSD: 5}}, false},
+		{Limits{GlobalDaily: Limit{Tokens: 10}}, false},
+	} {
+		if got := tc.limits.Uncapped(); got != tc.want {
+			t.Errorf("%+v.Uncapped() = %v, want %v", tc.limits, got, tc.want)
+		}
+	}
+}
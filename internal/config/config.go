@@ -1,88 +1,264 @@
+// Package config resolves the service configuration. Each setting can come
+// from, in increasing precedence: its default, an optional YAML or TOML
+// config file, the environment and a command-line flag.
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
 	"os"
-	"strings"
+	"slices"
 	"time"
+
+	"github.com/randomtoy/taas-go/internal/budget"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+	"github.com/randomtoy/taas-go/internal/ratelimit"
+	"github.com/randomtoy/taas-go/internal/routing"
 )
 
 type Config struct {
-	HTTPAddr           string
-	LogLevel           slog.Level
-	LLMProvider        string
-	LLMModel           string
-	LLMFallbackModels  []string
-	OpenRouterAPIKey   string
-	OpenRouterBaseURL  string
-	LLMTimeout         time.Duration
+	HTTPAddr             string
+	LogLevel             slog.Level
+	LLMProvider          string
+	LLMModel             string
+	LLMFallbackModels    []string
+	OpenRouterAPIKey     string
+	OpenRouterBaseURL    string
+	OpenAIAPIKey         string
+	OpenAIBaseURL        string
+	OpenAIAuthHeader     string
+	OpenAIAuthScheme     string
+	OpenAIHeaders        map[string]string
+	AnthropicAPIKey      string
+	AnthropicBaseURL     string
+	AnthropicVersion     string
+	LLMParams            ports.GenerationParams
+	LLMModelParams       map[string]ports.GenerationParams
+	LLMLengthMaxTokens   map[string]int
+	LLMTimeout           time.Duration
+	LLMStructuredOutput  bool
+	PromptsDir           string
+	ReplayDir            string
+	ReplayRecord         bool
+	AdminToken           string
+	Budget               budget.Limits
+	BudgetAction         budget.Action
+	BudgetDowngradeModel string
+	ReadingTTL           time.Duration
+	ReadingMaxEntries    int
+	MaxFollowUps         int
+	JobWorkers           int
+	JobQueueDepth        int
+	JobTTL               time.Duration
+	WebhookSecret        string
+	WebhookMaxAttempts   int
+	WebhookTimeout       time.Duration
+	WebhookAllowedHosts  []string
+	ShutdownTimeout      time.Duration
+	MaxBodyBytes         int
+	BatchMaxItems        int
+	IdempotencyTTL       time.Duration
+	BatchConcurrency     int
+	ValidateResponses    bool
+	HealthInterval       time.Duration
+	HealthTimeout        time.Duration
+	RateLimits           ratelimit.Limits
+	TrustedProxies       []*net.IPNet
+	LLMMaxInFlight       int
+	Routing              routing.Config
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAge           time.Duration
+	HSTSMaxAge           time.Duration
+
+	// Command line only.
+	ConfigFile  string
+	PrintConfig bool
 }
 
-func Load() (Config, error) {
-	c := Config{
-		HTTPAddr:          envOr("HTTP_ADDR", ":8080"),
-		LLMProvider:       envOr("LLM_PROVIDER", "openrouter"),
-		LLMModel:          envOr("LLM_MODEL", "qwen/qwen3-4b:free"),
-		OpenRouterAPIKey:  os.Getenv("OPENROUTER_API_KEY"),
-		OpenRouterBaseURL: envOr("OPENROUTER_BASE_URL", "https://openrouter.ai/api/v1"),
-		LLMFallbackModels: parseFallbackModels(os.Getenv("LLM_FALLBACK_MODELS")),
-		LLMTimeout:        10 * time.Second,
+func defaults() Config {
+	return Config{
+		HTTPAddr:           ":8080",
+		LogLevel:           slog.LevelInfo,
+		LLMProvider:        "openrouter",
+		LLMModel:           "qwen/qwen3-4b:free",
+		OpenRouterBaseURL:  "https://openrouter.ai/api/v1",
+		OpenAIAuthHeader:   "Authorization",
+		OpenAIAuthScheme:   "Bearer",
+		OpenAIHeaders:      map[string]string{},
+		AnthropicBaseURL:   "https://api.anthropic.com/v1",
+		AnthropicVersion:   "2023-06-01",
+		LLMModelParams:     map[string]ports.GenerationParams{},
+		LLMLengthMaxTokens: map[string]int{"short": 600, "medium": 1200, "long": 2400},
+		LLMTimeout:         10 * time.Second,
+		Budget:             budget.Limits{Prices: map[string]float64{}},
+		BudgetAction:       budget.ActionReject,
+		ReadingTTL:         24 * time.Hour,
+		ReadingMaxEntries:  10000,
+		MaxFollowUps:       5,
+		JobWorkers:         4,
+		JobQueueDepth:      100,
+		JobTTL:             time.Hour,
+		WebhookMaxAttempts: 5,
+		WebhookTimeout:     10 * time.Second,
+		ShutdownTimeout:    10 * time.Second,
+		MaxBodyBytes:       1 << 20,
+		BatchMaxItems:      100,
+		BatchConcurrency:   4,
+		IdempotencyTTL:     24 * time.Hour,
+		HealthInterval:     30 * time.Second,
+		HealthTimeout:      5 * time.Second,
+		RateLimits: ratelimit.Limits{
+			PerIP:  ratelimit.Rate{N: 60, Period: time.Minute},
+			Routes: map[string]ratelimit.Rate{},
+		},
+		LLMMaxInFlight:     16,
+		CORSAllowedMethods: []string{"GET", "POST"},
+		CORSAllowedHeaders: []string{"Content-Type", "X-Api-Key", "X-Request-Id", "Idempotency-Key"},
+		CORSMaxAge:         10 * time.Minute,
+		Routing: routing.Config{
+			Targets: map[string]routing.Spec{},
+			Policy:  routing.PolicyFallback,
+			Weights: map[string]int{},
+			ByLang:  map[string]string{},
+			ByCards: map[string]string{},
+		},
 	}
+}
 
-	if v := os.Getenv("LLM_TIMEOUT"); v != "" {
-		d, err := time.ParseDuration(v)
-		if err != nil {
-			return Config{}, fmt.Errorf("invalid LLM_TIMEOUT %q: %w", v, err)
+// Load resolves the configuration from defaults, the config file named by
+// --config or CONFIG_FILE, the environment and the flags in args, in that
+// order of precedence. It reports every invalid value at once.
+func Load(args []string) (Config, error) {
+	c := defaults()
+	flags := make(map[string]string)
+	fs := flag.NewFlagSet("tarotd", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigFile, "config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	fs.BoolVar(&c.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	for _, s := range settings {
+		name := s.flag()
+		fs.Func(name, "overrides "+s.env, func(v string) error { flags["--"+name] = v; return nil })
+		if s.secret {
+			fs.Func(name+"-file", "reads "+s.env+" from a file", func(v string) error { flags["--"+name+"-file"] = v; return nil })
 		}
-		c.LLMTimeout = d
 	}
-
-	level, err := parseLogLevel(envOr("LOG_LEVEL", "info"))
-	if err != nil {
+	if err := fs.Parse(args); err != nil {
 		return Config{}, err
 	}
-	c.LogLevel = level
+	if fs.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
 
-	if c.LLMProvider == "openrouter" && c.OpenRouterAPIKey == "" {
-		return Config{}, fmt.Errorf("OPENROUTER_API_KEY is required when LLM_PROVIDER=openrouter")
+	var errs []error
+	sources := []source{envSource(), flagSource(flags)}
+	if c.ConfigFile != "" {
+		file, unknown, err := fileSource(c.ConfigFile)
+		if err != nil {
+			return Config{}, err
+		}
+		for _, key := range unknown {
+			errs = append(errs, fmt.Errorf("unknown setting %q in %s", key, c.ConfigFile))
+		}
+		sources = append([]source{file}, sources...)
 	}
 
+	for _, s := range settings {
+		var v, from string
+		var found bool
+		for _, src := range sources {
+			sv, sfrom, ok, err := src.value(s)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if ok {
+				v, from, found = sv, sfrom, true
+			}
+		}
+		if !found {
+			continue
+		}
+		if err := s.set(&c, v); err != nil {
+			if s.secret {
+				v = redacted
+			}
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", from, v, err))
+		}
+	}
+
+	errs = append(errs, c.validate()...)
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
 	return c, nil
 }
 
-func envOr(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
+// Validate reports every rule c breaks, such as a provider without its API
+// key, as one error. Load already validates; a configuration merged from
+// two loaded ones, as on reload, has to be validated again.
+func (c Config) Validate() error {
+	return errors.Join(c.validate()...)
 }
 
-func parseFallbackModels(s string) []string {
-	if s == "" {
-		return nil
-	}
-	var models []string
-	for _, m := range strings.Split(s, ",") {
-		m = strings.TrimSpace(m)
-		if m != "" {
-			models = append(models, m)
+// validate checks rules that span settings or forbid values a single
+// setting's parser accepts.
+func (c *Config) validate() []error {
+	var errs []error
+	if c.JobWorkers < 1 {
+		errs = append(errs, fmt.Errorf("jobs.workers (JOB_WORKERS) must be at least 1"))
+	}
+	if c.MaxBodyBytes < 1 {
+		errs = append(errs, fmt.Errorf("server.max_body_bytes (MAX_BODY_BYTES) must be at least 1"))
+	}
+	if c.BatchConcurrency < 1 {
+		errs = append(errs, fmt.Errorf("batch.concurrency (BATCH_CONCURRENCY) must be at least 1"))
+	}
+	if c.HealthInterval <= 0 || c.HealthTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("health.interval (HEALTH_CHECK_INTERVAL) and health.timeout (HEALTH_CHECK_TIMEOUT) must be positive"))
+	}
+	if c.BudgetAction == budget.ActionDowngrade && c.BudgetDowngradeModel == "" {
+		errs = append(errs, fmt.Errorf("budget.downgrade_model (BUDGET_DOWNGRADE_MODEL) is required when budget.action is downgrade"))
+	}
+	if !slices.Contains([]string{"openrouter", "openai", "anthropic", "replay"}, c.LLMProvider) {
+		errs = append(errs, fmt.Errorf("llm.provider (LLM_PROVIDER) must be openrouter, openai, anthropic or replay, not %q", c.LLMProvider))
+	}
+	// With routing, the targets decide which providers are used.
+	providers := []string{c.LLMProvider}
+	if c.Routing.Enabled() {
+		providers = c.Routing.UsedProviders()
+	}
+	for _, p := range providers {
+		switch {
+		case p == "openrouter" && c.OpenRouterAPIKey == "":
+			errs = append(errs, fmt.Errorf("openrouter.api_key (OPENROUTER_API_KEY) is required for the openrouter provider"))
+		case p == "openai" && c.OpenAIBaseURL == "":
+			errs = append(errs, fmt.Errorf("openai.base_url (OPENAI_BASE_URL) is required for the openai provider"))
+		case p == "anthropic" && c.AnthropicAPIKey == "":
+			errs = append(errs, fmt.Errorf("anthropic.api_key (ANTHROPIC_API_KEY) is required for the anthropic provider"))
+		case p == "replay" && c.ReplayDir == "":
+			errs = append(errs, fmt.Errorf("replay.dir (REPLAY_DIR) is required for the replay provider"))
 		}
 	}
-	return models
-}
-
-func parseLogLevel(s string) (slog.Level, error) {
-	switch strings.ToLower(s) {
-	case "debug":
-		return slog.LevelDebug, nil
-	case "info":
-		return slog.LevelInfo, nil
-	case "warn":
-		return slog.LevelWarn, nil
-	case "error":
-		return slog.LevelError, nil
-	default:
-		return 0, fmt.Errorf("invalid LOG_LEVEL %q", s)
+	if c.ReplayRecord && c.ReplayDir == "" {
+		errs = append(errs, fmt.Errorf("replay.dir (REPLAY_DIR) is required when replay.record is set"))
+	}
+	if err := c.Routing.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("routing: %w", err))
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.LLMLengthMaxTokens)) {
+		if l, _ := domain.ParseLength(name); l == "" {
+			errs = append(errs, fmt.Errorf("llm.length_max_tokens (LLM_LENGTH_MAX_TOKENS) has unknown length %q; want short, medium or long", name))
+		}
+	}
+	if c.CORSAllowCredentials && slices.Contains(c.CORSAllowedOrigins, "*") {
+		errs = append(errs, fmt.Errorf("cors.allow_credentials (CORS_ALLOW_CREDENTIALS) cannot be used when cors.allowed_origins is *"))
 	}
+	return errs
 }
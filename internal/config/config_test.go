@@ -0,0 +1,352 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/config"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "key")
+
+	c, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HTTPAddr != ":8080" || c.LLMTimeout != 10*time.Second || c.JobWorkers != 4 {
+		t.Errorf("unexpected defaults: %+v", c)
+	}
+}
+
+func TestLoad_Layering(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+llm:
+  model: from-file
+  fallback_models: [a, b]
+  timeout: 20s
+  prices:
+    from-file: 0.5
+openrouter:
+  api_key: file-key
+jobs:
+  workers: 2
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("LLM_MODEL", "from-env")
+	t.Setenv("JOB_WORKERS", "3")
+
+	c, err := config.Load([]string{"--llm-model", "from-flag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LLMModel != "from-flag" {
+		t.Errorf("LLMModel = %q, want the flag", c.LLMModel)
+	}
+	if c.JobWorkers != 3 {
+		t.Errorf("JobWorkers = %d, want the env value", c.JobWorkers)
+	}
+	if c.LLMTimeout != 20*time.Second || c.OpenRouterAPIKey != "file-key" {
+		t.Errorf("file values not applied: %+v", c)
+	}
+	if !reflect.DeepEqual(c.LLMFallbackModels, []string{"a", "b"}) {
+		t.Errorf("LLMFallbackModels = %v", c.LLMFallbackModels)
+	}
+	if c.Budget.Prices["from-file"] != 0.5 {
+		t.Errorf("Prices = %v", c.Budget.Prices)
+	}
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[server]
+addr = ":9090"
+log_level = "debug"
+
+[openrouter]
+api_key = "key"
+
+[budget.global_daily]
+usd = 2.5
+`)
+	c, err := config.Load([]string{"--config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HTTPAddr != ":9090" || c.LogLevel.String() != "DEBUG" || c.Budget.GlobalDaily.CostUSD != 2.5 {
+		t.Errorf("unexpected config: %+v", c)
+	}
+}
+
+func TestLoad_ReportsAllProblems(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+llm:
+  timeout: soon
+  temprature: 2
+jobs:
+  workers: 0
+`)
+	t.Setenv("BATCH_MAX_ITEMS", "-1")
+
+	_, err := config.Load([]string{"--config", path})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{
+		`invalid llm.timeout "soon"`,
+		`unknown setting "llm.temprature"`,
+		`invalid BATCH_MAX_ITEMS "-1"`,
+		"jobs.workers (JOB_WORKERS) must be at least 1",
+		"openrouter.api_key (OPENROUTER_API_KEY) is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoad_SecretFromFile(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY_FILE", writeFile(t, "api-key", "mounted-key\n"))
+
+	c, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.OpenRouterAPIKey != "mounted-key" {
+		t.Errorf("OpenRouterAPIKey = %q", c.OpenRouterAPIKey)
+	}
+
+	t.Setenv("OPENROUTER_API_KEY", "inline")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "set only one of") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestPrint_RedactsAndRoundTrips(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "sk-secret")
+	t.Setenv("LLM_FALLBACK_MODELS", "a,b")
+	t.Setenv("LLM_PRICES", "a=1.5")
+	t.Setenv("LLM_TEMPERATURE", "0")
+	t.Setenv("LLM_MODEL_PARAMS", "qwen/qwen3-4b:free=temperature:0.3;max_tokens:800")
+	t.Setenv("OPENAI_AUTH_SCHEME", "none")
+	want, err := config.Load([]string{"--print-config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := config.Print(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "sk-secret") {
+		t.Fatalf("secret printed:\n%s", buf.String())
+	}
+
+	os.Unsetenv("LLM_FALLBACK_MODELS")
+	os.Unsetenv("LLM_PRICES")
+	os.Unsetenv("LLM_TEMPERATURE")
+	os.Unsetenv("LLM_MODEL_PARAMS")
+	os.Unsetenv("OPENAI_AUTH_SCHEME")
+	got, err := config.Load([]string{"--config", writeFile(t, "printed.yaml", buf.String()), "--openrouter-api-key", "sk-secret"})
+	if err != nil {
+		t.Fatalf("printed config does not load: %v\n%s", err, buf.String())
+	}
+	got.ConfigFile, got.PrintConfig = want.ConfigFile, want.PrintConfig
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the config:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := config.Config{HTTPAddr: ":8080", LLMModel: "a", OpenRouterAPIKey: "sk-old"}
+	next := config.Config{HTTPAddr: ":9090", LLMModel: "a", OpenRouterAPIKey: "sk-new"}
+
+	changes := config.Diff(old, next)
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if ch := changes[0]; ch.Key != "server.addr" || ch.Reloadable || ch.Old != ":8080" || ch.New != ":9090" {
+		t.Errorf("addr change = %+v", ch)
+	}
+	if ch := changes[1]; ch.Key != "openrouter.api_key" || !ch.Reloadable || ch.Old != "[redacted]" || ch.New != "[redacted]" {
+		t.Errorf("api key change = %+v", ch)
+	}
+
+	got := config.Reload(old, next)
+	if got.HTTPAddr != ":8080" || got.OpenRouterAPIKey != "sk-new" {
+		t.Errorf("Reload = %+v", got)
+	}
+}
+
+func TestLoad_CORSOrigins(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://App.example.com/, http://localhost:3000")
+
+	c, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.CORSAllowedOrigins, []string{"https://app.example.com", "http://localhost:3000"}) {
+		t.Errorf("CORSAllowedOrigins = %v", c.CORSAllowedOrigins)
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "app.example.com")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "is not an origin") {
+		t.Errorf("expected origin error, got %v", err)
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "cannot be used when cors.allowed_origins is *") {
+		t.Errorf("expected credentials error, got %v", err)
+	}
+}
+
+func TestLoad_Providers(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "openai")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "openai.base_url (OPENAI_BASE_URL) is required") {
+		t.Errorf("expected base URL error, got %v", err)
+	}
+
+	t.Setenv("OPENAI_BASE_URL", "http://localhost:8000/v1")
+	t.Setenv("OPENAI_HEADERS", "X-Tenant=acme")
+	t.Setenv("LLM_TEMPERATURE", "0.2")
+	c, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.OpenAIHeaders["X-Tenant"] != "acme" || c.OpenAIAuthScheme != "Bearer" {
+		t.Errorf("unexpected openai settings: %+v", c)
+	}
+	if c.LLMParams.Temperature == nil || *c.LLMParams.Temperature != 0.2 || c.LLMParams.TopP != nil {
+		t.Errorf("LLMParams = %+v", c.LLMParams)
+	}
+
+	t.Setenv("LLM_PROVIDER", "anthropic")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "anthropic.api_key (ANTHROPIC_API_KEY) is required") {
+		t.Errorf("expected API key error, got %v", err)
+	}
+
+	t.Setenv("LLM_PROVIDER", "ollama")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "must be openrouter, openai, anthropic or replay") {
+		t.Errorf("expected provider error, got %v", err)
+	}
+
+	t.Setenv("LLM_PROVIDER", "replay")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "replay.dir (REPLAY_DIR) is required for the replay provider") {
+		t.Errorf("expected replay dir error, got %v", err)
+	}
+	t.Setenv("REPLAY_DIR", "testdata/replay")
+	if _, err := config.Load(nil); err != nil {
+		t.Errorf("replay needs no API key: %v", err)
+	}
+}
+
+func TestLoad_ReplayRecord(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	t.Setenv("REPLAY_RECORD", "true")
+	if _, err := config.Load(nil); err == nil || !strings.Contains(err.Error(), "replay.dir (REPLAY_DIR) is required when replay.record is set") {
+		t.Errorf("expected replay dir error, got %v", err)
+	}
+	c, err := config.Load([]string{"--replay-dir", "fixtures"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.ReplayRecord || c.ReplayDir != "fixtures" {
+		t.Errorf("replay settings = %v, %q", c.ReplayRecord, c.ReplayDir)
+	}
+}
+
+func TestLoad_GenerationParams(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	path := writeFile(t, "config.yaml", `
+llm:
+  max_tokens: 1500
+  model_params:
+    qwen/qwen3-4b:free:
+      temperature: 0.3
+      seed: 7
+    claude-sonnet-4-5:
+      max_tokens: 900
+  length_max_tokens:
+    short: 300
+`)
+	c, err := config.Load([]string{"--config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qwen := c.LLMModelParams["qwen/qwen3-4b:free"]
+	if qwen.Temperature == nil || *qwen.Temperature != 0.3 || qwen.Seed == nil || *qwen.Seed != 7 || qwen.MaxTokens != 0 {
+		t.Errorf("qwen params = %+v", qwen)
+	}
+	if c.LLMModelParams["claude-sonnet-4-5"].MaxTokens != 900 || c.LLMParams.MaxTokens != 1500 {
+		t.Errorf("LLMModelParams = %+v, LLMParams = %+v", c.LLMModelParams, c.LLMParams)
+	}
+	if !reflect.DeepEqual(c.LLMLengthMaxTokens, map[string]int{"short": 300}) {
+		t.Errorf("LLMLengthMaxTokens = %v", c.LLMLengthMaxTokens)
+	}
+
+	t.Setenv("LLM_MODEL_PARAMS", "m=temperature:hot")
+	t.Setenv("LLM_LENGTH_MAX_TOKENS", "epic=5000")
+	_, err = config.Load(nil)
+	if err == nil || !strings.Contains(err.Error(), `"temperature:hot": want a non-negative number`) {
+		t.Errorf("expected a model params error, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), `unknown length "epic"`) {
+		t.Errorf("expected a length error, got %v", err)
+	}
+}
+
+func TestLoad_Routing(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	path := writeFile(t, "config.yaml", `
+openai:
+  base_url: http://localhost:11434/v1
+routing:
+  targets:
+    local: openai:llama3.1
+    big: anthropic:claude-sonnet-4-5
+    canned: template
+  order: [local, canned]
+  by_lang:
+    ru: big
+  by_cards:
+    7+: big
+`)
+	_, err := config.Load([]string{"--config", path})
+	if err == nil || !strings.Contains(err.Error(), "anthropic.api_key (ANTHROPIC_API_KEY) is required") {
+		t.Fatalf("expected the anthropic target to need a key, got %v", err)
+	}
+	if strings.Contains(err.Error(), "OPENROUTER_API_KEY") {
+		t.Errorf("openrouter is not used by any target: %v", err)
+	}
+
+	c, err := config.Load([]string{"--config", path, "--anthropic-api-key", "sk-ant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Routing.Targets["local"].Model != "llama3.1" || c.Routing.ByCards["7+"] != "big" || c.Routing.ByLang["ru"] != "big" {
+		t.Errorf("Routing = %+v", c.Routing)
+	}
+
+	t.Setenv("LLM_ROUTING_ORDER", "local,missing")
+	if _, err := config.Load([]string{"--config", path, "--anthropic-api-key", "sk-ant"}); err == nil || !strings.Contains(err.Error(), `order refers to unknown target "missing"`) {
+		t.Errorf("expected unknown target error, got %v", err)
+	}
+}
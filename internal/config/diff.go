@@ -0,0 +1,48 @@
+package config
+
+import "reflect"
+
+// Change is a setting whose value differs between two configurations.
+// Secret values are redacted.
+type Change struct {
+	Key        string `json:"key"`
+	Env        string `json:"env"`
+	Old        any    `json:"old"`
+	New        any    `json:"new"`
+	Reloadable bool   `json:"reloadable"`
+}
+
+// Diff lists the settings that differ between old and next, in the order
+// they are documented.
+func Diff(old, next Config) []Change {
+	var changes []Change
+	for _, s := range settings {
+		a, b := s.get(&old), s.get(&next)
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+		if s.secret {
+			a, b = redact(a), redact(b)
+		}
+		changes = append(changes, Change{Key: s.key, Env: s.env, Old: a, New: b, Reloadable: s.reload})
+	}
+	return changes
+}
+
+func redact(v any) any {
+	if v == "" {
+		return v
+	}
+	return redacted
+}
+
+// Reload returns current with its reloadable settings taken from next.
+// The other settings keep their values until the process restarts.
+func Reload(current, next Config) Config {
+	for _, s := range settings {
+		if s.reload {
+			s.copy(&current, &next)
+		}
+	}
+	return current
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[redacted]"
+
+// Print writes the effective configuration as a YAML config file, with
+// secrets redacted.
+func Print(w io.Writer, c Config) error {
+	doc := map[string]any{}
+	for _, s := range settings {
+		v := s.get(&c)
+		if s.secret && v != "" {
+			v = redacted
+		}
+		node := doc
+		parts := strings.Split(s.key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = v
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,498 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/budget"
+	"github.com/randomtoy/taas-go/internal/ports"
+	"github.com/randomtoy/taas-go/internal/ratelimit"
+	"github.com/randomtoy/taas-go/internal/routing"
+)
+
+// setting is one configuration value. Every source spells it differently:
+// key in the config file ("llm.timeout"), env in the environment
+// ("LLM_TIMEOUT") and the key with dashes as a flag ("--llm-timeout").
+// All sources hand set the same string form, so a value is validated the
+// same way wherever it came from.
+type setting struct {
+	key    string
+	env    string
+	secret bool // may be read from a file; redacted when printed
+	table  bool // a map in the config file, e.g. per-model prices
+	reload bool // may change on a running server
+	set    func(c *Config, v string) error
+	get    func(c *Config) any
+	copy   func(dst, src *Config)
+}
+
+func (s setting) flag() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+// reloadable marks s as safe to change without a restart.
+func (s setting) reloadable() setting {
+	s.reload = true
+	return s
+}
+
+var settings = []setting{
+	stringSetting("server.addr", "HTTP_ADDR", func(c *Config) *string { return &c.HTTPAddr }),
+	field("server.log_level", "LOG_LEVEL", func(c *Config) *slog.Level { return &c.LogLevel },
+		parseLogLevel, func(l slog.Level) any { return strings.ToLower(l.String()) }).reloadable(),
+	durationSetting("server.shutdown_timeout", "SHUTDOWN_TIMEOUT", func(c *Config) *time.Duration { return &c.ShutdownTimeout }),
+	intSetting("server.max_body_bytes", "MAX_BODY_BYTES", func(c *Config) *int { return &c.MaxBodyBytes }),
+
+	stringSetting("llm.provider", "LLM_PROVIDER", func(c *Config) *string { return &c.LLMProvider }),
+	stringSetting("llm.model", "LLM_MODEL", func(c *Config) *string { return &c.LLMModel }).reloadable(),
+	field("llm.fallback_models", "LLM_FALLBACK_MODELS", func(c *Config) *[]string { return &c.LLMFallbackModels },
+		func(v string) ([]string, error) { return parseList(v), nil }, nil).reloadable(),
+	durationSetting("llm.timeout", "LLM_TIMEOUT", func(c *Config) *time.Duration { return &c.LLMTimeout }).reloadable(),
+	boolSetting("llm.structured_output", "LLM_STRUCTURED_OUTPUT", func(c *Config) *bool { return &c.LLMStructuredOutput }).reloadable(),
+	stringSetting("llm.prompts_dir", "PROMPTS_DIR", func(c *Config) *string { return &c.PromptsDir }).reloadable(),
+	tableSetting("llm.prices", "LLM_PRICES", func(c *Config) *map[string]float64 { return &c.Budget.Prices }, parsePrices, nil).reloadable(),
+	optionalFloatSetting("llm.temperature", "LLM_TEMPERATURE", func(c *Config) **float64 { return &c.LLMParams.Temperature }).reloadable(),
+	optionalFloatSetting("llm.top_p", "LLM_TOP_P", func(c *Config) **float64 { return &c.LLMParams.TopP }).reloadable(),
+	intSetting("llm.max_tokens", "LLM_MAX_TOKENS", func(c *Config) *int { return &c.LLMParams.MaxTokens }).reloadable(),
+	field("llm.seed", "LLM_SEED", func(c *Config) **int64 { return &c.LLMParams.Seed }, parseOptionalInt, showOptional[int64]).reloadable(),
+	tableSetting("llm.model_params", "LLM_MODEL_PARAMS", func(c *Config) *map[string]ports.GenerationParams { return &c.LLMModelParams },
+		func(v string) (map[string]ports.GenerationParams, error) {
+			return parseTable(v, "model=name:value;...", parseGenerationParams)
+		}, showModelParams).reloadable(),
+	tableSetting("llm.length_max_tokens", "LLM_LENGTH_MAX_TOKENS", func(c *Config) *map[string]int { return &c.LLMLengthMaxTokens },
+		func(v string) (map[string]int, error) { return parseTable(v, "length=max_tokens", parseCount) }, nil).reloadable(),
+	intSetting("llm.max_in_flight", "LLM_MAX_IN_FLIGHT", func(c *Config) *int { return &c.LLMMaxInFlight }).reloadable(),
+	tableSetting("routing.targets", "LLM_ROUTING_TARGETS", func(c *Config) *map[string]routing.Spec { return &c.Routing.Targets },
+		func(v string) (map[string]routing.Spec, error) {
+			return parseTable(v, "name=provider:model", routing.ParseSpec)
+		},
+		func(targets map[string]routing.Spec) any {
+			out := make(map[string]string, len(targets))
+			for name, spec := range targets {
+				out[name] = spec.String()
+			}
+			return out
+		}).reloadable(),
+	field("routing.order", "LLM_ROUTING_ORDER", func(c *Config) *[]string { return &c.Routing.Order },
+		func(v string) ([]string, error) { return parseList(v), nil }, nil).reloadable(),
+	field("routing.policy", "LLM_ROUTING_POLICY", func(c *Config) *routing.Policy { return &c.Routing.Policy },
+		routing.ParsePolicy, func(p routing.Policy) any { return string(p) }).reloadable(),
+	tableSetting("routing.weights", "LLM_ROUTING_WEIGHTS", func(c *Config) *map[string]int { return &c.Routing.Weights },
+		func(v string) (map[string]int, error) { return parseTable(v, "name=weight", parseCount) }, nil).reloadable(),
+	tableSetting("routing.by_lang", "LLM_ROUTING_BY_LANG", func(c *Config) *map[string]string { return &c.Routing.ByLang },
+		func(v string) (map[string]string, error) { return parseTable(v, "lang=target", parseString) }, nil).reloadable(),
+	tableSetting("routing.by_cards", "LLM_ROUTING_BY_CARDS", func(c *Config) *map[string]string { return &c.Routing.ByCards },
+		func(v string) (map[string]string, error) { return parseTable(v, "cards=target", parseString) }, nil).reloadable(),
+	stringSetting("replay.dir", "REPLAY_DIR", func(c *Config) *string { return &c.ReplayDir }).reloadable(),
+	boolSetting("replay.record", "REPLAY_RECORD", func(c *Config) *bool { return &c.ReplayRecord }).reloadable(),
+	secretSetting("openrouter.api_key", "OPENROUTER_API_KEY", func(c *Config) *string { return &c.OpenRouterAPIKey }).reloadable(),
+	stringSetting("openrouter.base_url", "OPENROUTER_BASE_URL", func(c *Config) *string { return &c.OpenRouterBaseURL }).reloadable(),
+	secretSetting("openai.api_key", "OPENAI_API_KEY", func(c *Config) *string { return &c.OpenAIAPIKey }).reloadable(),
+	stringSetting("openai.base_url", "OPENAI_BASE_URL", func(c *Config) *string { return &c.OpenAIBaseURL }).reloadable(),
+	stringSetting("openai.auth_header", "OPENAI_AUTH_HEADER", func(c *Config) *string { return &c.OpenAIAuthHeader }).reloadable(),
+	field("openai.auth_scheme", "OPENAI_AUTH_SCHEME", func(c *Config) *string { return &c.OpenAIAuthScheme },
+		func(v string) (string, error) {
+			if strings.EqualFold(v, "none") {
+				return "", nil
+			}
+			return v, nil
+		}, func(scheme string) any {
+			if scheme == "" {
+				return "none"
+			}
+			return scheme
+		}).reloadable(),
+	tableSetting("openai.headers", "OPENAI_HEADERS", func(c *Config) *map[string]string { return &c.OpenAIHeaders },
+		func(v string) (map[string]string, error) { return parseTable(v, "Name=value", parseString) }, nil).reloadable(),
+	secretSetting("anthropic.api_key", "ANTHROPIC_API_KEY", func(c *Config) *string { return &c.AnthropicAPIKey }).reloadable(),
+	stringSetting("anthropic.base_url", "ANTHROPIC_BASE_URL", func(c *Config) *string { return &c.AnthropicBaseURL }).reloadable(),
+	stringSetting("anthropic.version", "ANTHROPIC_VERSION", func(c *Config) *string { return &c.AnthropicVersion }).reloadable(),
+
+	secretSetting("admin.token", "ADMIN_TOKEN", func(c *Config) *string { return &c.AdminToken }),
+
+	field("budget.action", "BUDGET_ACTION", func(c *Config) *budget.Action { return &c.BudgetAction },
+		budget.ParseAction, func(a budget.Action) any { return string(a) }).reloadable(),
+	stringSetting("budget.downgrade_model", "BUDGET_DOWNGRADE_MODEL", func(c *Config) *string { return &c.BudgetDowngradeModel }).reloadable(),
+	int64Setting("budget.global_hourly.tokens", "BUDGET_GLOBAL_HOURLY_TOKENS", func(c *Config) *int64 { return &c.Budget.GlobalHourly.Tokens }).reloadable(),
+	floatSetting("budget.global_hourly.usd", "BUDGET_GLOBAL_HOURLY_USD", func(c *Config) *float64 { return &c.Budget.GlobalHourly.CostUSD }).reloadable(),
+	int64Setting("budget.global_daily.tokens", "BUDGET_GLOBAL_DAILY_TOKENS", func(c *Config) *int64 { return &c.Budget.GlobalDaily.Tokens }).reloadable(),
+	floatSetting("budget.global_daily.usd", "BUDGET_GLOBAL_DAILY_USD", func(c *Config) *float64 { return &c.Budget.GlobalDaily.CostUSD }).reloadable(),
+	int64Setting("budget.key_hourly.tokens", "BUDGET_KEY_HOURLY_TOKENS", func(c *Config) *int64 { return &c.Budget.KeyHourly.Tokens }).reloadable(),
+	floatSetting("budget.key_hourly.usd", "BUDGET_KEY_HOURLY_USD", func(c *Config) *float64 { return &c.Budget.KeyHourly.CostUSD }).reloadable(),
+	int64Setting("budget.key_daily.tokens", "BUDGET_KEY_DAILY_TOKENS", func(c *Config) *int64 { return &c.Budget.KeyDaily.Tokens }).reloadable(),
+	floatSetting("budget.key_daily.usd", "BUDGET_KEY_DAILY_USD", func(c *Config) *float64 { return &c.Budget.KeyDaily.CostUSD }).reloadable(),
+
+	rateSetting("ratelimit.global", "RATE_LIMIT_GLOBAL", func(c *Config) *ratelimit.Rate { return &c.RateLimits.Global }).reloadable(),
+	rateSetting("ratelimit.per_ip", "RATE_LIMIT_PER_IP", func(c *Config) *ratelimit.Rate { return &c.RateLimits.PerIP }).reloadable(),
+	tableSetting("ratelimit.routes", "RATE_LIMIT_ROUTES", func(c *Config) *map[string]ratelimit.Rate { return &c.RateLimits.Routes },
+		parseRouteRates, func(routes map[string]ratelimit.Rate) any {
+			out := make(map[string]string, len(routes))
+			for route, rate := range routes {
+				out[route] = rate.String()
+			}
+			return out
+		}).reloadable(),
+	field("ratelimit.trusted_proxies", "TRUSTED_PROXIES", func(c *Config) *[]*net.IPNet { return &c.TrustedProxies },
+		parseCIDRs, func(nets []*net.IPNet) any {
+			out := make([]string, len(nets))
+			for i, n := range nets {
+				out[i] = n.String()
+			}
+			return out
+		}),
+
+	field("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", func(c *Config) *[]string { return &c.CORSAllowedOrigins }, parseOrigins, nil),
+	field("cors.allowed_methods", "CORS_ALLOWED_METHODS", func(c *Config) *[]string { return &c.CORSAllowedMethods },
+		func(v string) ([]string, error) { return parseList(strings.ToUpper(v)), nil }, nil),
+	field("cors.allowed_headers", "CORS_ALLOWED_HEADERS", func(c *Config) *[]string { return &c.CORSAllowedHeaders },
+		func(v string) ([]string, error) { return parseList(v), nil }, nil),
+	boolSetting("cors.allow_credentials", "CORS_ALLOW_CREDENTIALS", func(c *Config) *bool { return &c.CORSAllowCredentials }),
+	durationSetting("cors.max_age", "CORS_MAX_AGE", func(c *Config) *time.Duration { return &c.CORSMaxAge }),
+	durationSetting("security.hsts_max_age", "HSTS_MAX_AGE", func(c *Config) *time.Duration { return &c.HSTSMaxAge }),
+
+	durationSetting("readings.ttl", "READING_TTL", func(c *Config) *time.Duration { return &c.ReadingTTL }),
+	intSetting("readings.max_entries", "READING_MAX_ENTRIES", func(c *Config) *int { return &c.ReadingMaxEntries }),
+	intSetting("readings.max_followups", "MAX_FOLLOWUPS", func(c *Config) *int { return &c.MaxFollowUps }),
+
+	intSetting("jobs.workers", "JOB_WORKERS", func(c *Config) *int { return &c.JobWorkers }),
+	intSetting("jobs.queue_depth", "JOB_QUEUE_DEPTH", func(c *Config) *int { return &c.JobQueueDepth }),
+	durationSetting("jobs.ttl", "JOB_TTL", func(c *Config) *time.Duration { return &c.JobTTL }),
+
+	secretSetting("webhooks.secret", "WEBHOOK_SECRET", func(c *Config) *string { return &c.WebhookSecret }),
+	intSetting("webhooks.max_attempts", "WEBHOOK_MAX_ATTEMPTS", func(c *Config) *int { return &c.WebhookMaxAttempts }),
+	durationSetting("webhooks.timeout", "WEBHOOK_TIMEOUT", func(c *Config) *time.Duration { return &c.WebhookTimeout }),
+	field("webhooks.allowed_hosts", "WEBHOOK_ALLOWED_HOSTS", func(c *Config) *[]string { return &c.WebhookAllowedHosts },
+		func(v string) ([]string, error) { return parseList(v), nil }, nil),
+
+	intSetting("batch.max_items", "BATCH_MAX_ITEMS", func(c *Config) *int { return &c.BatchMaxItems }),
+	intSetting("batch.concurrency", "BATCH_CONCURRENCY", func(c *Config) *int { return &c.BatchConcurrency }),
+
+	durationSetting("idempotency.ttl", "IDEMPOTENCY_TTL", func(c *Config) *time.Duration { return &c.IdempotencyTTL }),
+	boolSetting("openapi.validate_responses", "OPENAPI_VALIDATE_RESPONSES", func(c *Config) *bool { return &c.ValidateResponses }),
+
+	durationSetting("health.interval", "HEALTH_CHECK_INTERVAL", func(c *Config) *time.Duration { return &c.HealthInterval }),
+	durationSetting("health.timeout", "HEALTH_CHECK_TIMEOUT", func(c *Config) *time.Duration { return &c.HealthTimeout }),
+}
+
+// field builds a setting stored at ptr. show converts the value for
+// printing and diffs; nil prints it as is.
+func field[T any](key, env string, ptr func(*Config) *T, parse func(string) (T, error), show func(T) any) setting {
+	if show == nil {
+		show = func(v T) any { return v }
+	}
+	return setting{
+		key: key, env: env,
+		set: func(c *Config, v string) error {
+			parsed, err := parse(v)
+			if err != nil {
+				return err
+			}
+			*ptr(c) = parsed
+			return nil
+		},
+		get:  func(c *Config) any { return show(*ptr(c)) },
+		copy: func(dst, src *Config) { *ptr(dst) = *ptr(src) },
+	}
+}
+
+func stringSetting(key, env string, ptr func(*Config) *string) setting {
+	return field(key, env, ptr, func(v string) (string, error) { return v, nil }, nil)
+}
+
+func secretSetting(key, env string, ptr func(*Config) *string) setting {
+	s := stringSetting(key, env, ptr)
+	s.secret = true
+	return s
+}
+
+func tableSetting[T any](key, env string, ptr func(*Config) *map[string]T, parse func(string) (map[string]T, error), show func(map[string]T) any) setting {
+	s := field(key, env, ptr, parse, show)
+	s.table = true
+	return s
+}
+
+func rateSetting(key, env string, ptr func(*Config) *ratelimit.Rate) setting {
+	return field(key, env, ptr, ratelimit.ParseRate, func(r ratelimit.Rate) any { return r.String() })
+}
+
+func durationSetting(key, env string, ptr func(*Config) *time.Duration) setting {
+	return field(key, env, ptr, func(v string) (time.Duration, error) {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("want a non-negative duration such as 30s")
+		}
+		return d, nil
+	}, func(d time.Duration) any { return d.String() })
+}
+
+func intSetting(key, env string, ptr func(*Config) *int) setting {
+	return field(key, env, ptr, func(v string) (int, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("want a non-negative integer")
+		}
+		return n, nil
+	}, nil)
+}
+
+func int64Setting(key, env string, ptr func(*Config) *int64) setting {
+	return field(key, env, ptr, func(v string) (int64, error) {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("want a non-negative integer")
+		}
+		return n, nil
+	}, nil)
+}
+
+func floatSetting(key, env string, ptr func(*Config) *float64) setting {
+	return field(key, env, ptr, func(v string) (float64, error) {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return 0, fmt.Errorf("want a non-negative number")
+		}
+		return f, nil
+	}, nil)
+}
+
+// optionalFloatSetting is a non-negative number that may be left unset,
+// so that zero can mean zero.
+func optionalFloatSetting(key, env string, ptr func(*Config) **float64) setting {
+	return field(key, env, ptr, parseOptionalFloat, showOptional[float64])
+}
+
+func parseOptionalFloat(v string) (*float64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		return nil, fmt.Errorf("want a non-negative number")
+	}
+	return &f, nil
+}
+
+func parseOptionalInt(v string) (*int64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("want an integer")
+	}
+	return &n, nil
+}
+
+func showOptional[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func boolSetting(key, env string, ptr func(*Config) *bool) setting {
+	return field(key, env, ptr, func(v string) (bool, error) {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("want true or false")
+		}
+		return b, nil
+	}, nil)
+}
+
+func parseLogLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("want debug, info, warn or error")
+	}
+}
+
+// parsePrices parses "model=usd_per_million_tokens,..." pairs.
+func parsePrices(s string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		model, raw, ok := strings.Cut(pair, "=")
+		price, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if !ok || err != nil || price < 0 {
+			return nil, fmt.Errorf("entry %q: want model=usd_per_million_tokens", pair)
+		}
+		prices[strings.TrimSpace(model)] = price
+	}
+	return prices, nil
+}
+
+// parseTable parses "key=value,..." pairs, where want describes a pair
+// for error messages.
+func parseTable[T any](s, want string, parse func(string) (T, error)) (map[string]T, error) {
+	table := make(map[string]T)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, raw, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("entry %q: want %s", pair, want)
+		}
+		v, err := parse(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", pair, err)
+		}
+		table[key] = v
+	}
+	return table, nil
+}
+
+func parseString(v string) (string, error) { return v, nil }
+
+func parseCount(v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("want a non-negative integer")
+	}
+	return n, nil
+}
+
+// parseGenerationParams parses "name:value;..." pairs such as
+// "temperature:0.3;max_tokens:800". Names are temperature, top_p,
+// max_tokens and seed.
+func parseGenerationParams(s string) (ports.GenerationParams, error) {
+	var p ports.GenerationParams
+	for _, pair := range strings.Split(s, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, raw, _ := strings.Cut(pair, ":")
+		raw = strings.TrimSpace(raw)
+		var err error
+		switch strings.TrimSpace(name) {
+		case "temperature":
+			p.Temperature, err = parseOptionalFloat(raw)
+		case "top_p":
+			p.TopP, err = parseOptionalFloat(raw)
+		case "max_tokens":
+			p.MaxTokens, err = parseCount(raw)
+		case "seed":
+			p.Seed, err = parseOptionalInt(raw)
+		default:
+			return ports.GenerationParams{}, fmt.Errorf("%q: want temperature, top_p, max_tokens or seed", pair)
+		}
+		if err != nil {
+			return ports.GenerationParams{}, fmt.Errorf("%q: %w", pair, err)
+		}
+	}
+	return p, nil
+}
+
+// showModelParams prints each model's parameters as a table of the fields
+// it sets.
+func showModelParams(models map[string]ports.GenerationParams) any {
+	out := make(map[string]map[string]any, len(models))
+	for model, p := range models {
+		fields := make(map[string]any)
+		if p.Temperature != nil {
+			fields["temperature"] = *p.Temperature
+		}
+		if p.TopP != nil {
+			fields["top_p"] = *p.TopP
+		}
+		if p.MaxTokens > 0 {
+			fields["max_tokens"] = p.MaxTokens
+		}
+		if p.Seed != nil {
+			fields["seed"] = *p.Seed
+		}
+		out[model] = fields
+	}
+	return out
+}
+
+// parseRouteRates parses "route=rate,..." pairs such as "/v1/tarot=10/1m".
+func parseRouteRates(s string) (map[string]ratelimit.Rate, error) {
+	routes := make(map[string]ratelimit.Rate)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		route, raw, ok := strings.Cut(pair, "=")
+		route = strings.TrimSpace(route)
+		if !ok || !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("entry %q: want route=rate such as /v1/tarot=10/1m", pair)
+		}
+		rate, err := ratelimit.ParseRate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", pair, err)
+		}
+		routes[route] = rate
+	}
+	return routes, nil
+}
+
+// parseCIDRs parses a comma-separated list of CIDR ranges.
+func parseCIDRs(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, raw := range strings.Split(s, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a CIDR range", raw)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// parseOrigins parses a comma-separated list of origins such as
+// "https://app.example.com", or "*".
+func parseOrigins(s string) ([]string, error) {
+	origins := parseList(s)
+	for i, o := range origins {
+		if o == "*" {
+			continue
+		}
+		u, err := url.Parse(o)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.TrimSuffix(u.Path, "/") != "" || u.RawQuery != "" {
+			return nil, fmt.Errorf("%q is not an origin such as https://app.example.com", o)
+		}
+		origins[i] = strings.ToLower(u.Scheme + "://" + u.Host)
+	}
+	return origins, nil
+}
+
+// parseList parses a comma-separated list, dropping empty entries.
+func parseList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
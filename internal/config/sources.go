@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// source is one layer of configuration: the config file, the environment
+// or the command line.
+type source struct {
+	// name spells a setting in this source, e.g. "LLM_TIMEOUT".
+	name func(s setting) string
+	// fileSuffix names the variant of a secret that holds a path to read
+	// it from, e.g. "_FILE" for OPENROUTER_API_KEY_FILE.
+	fileSuffix string
+	lookup     func(name string) (string, bool)
+}
+
+// value returns the raw value of s in this source, reading secrets from
+// files, and where it came from for error messages.
+func (src source) value(s setting) (v, from string, ok bool, err error) {
+	name := src.name(s)
+	v, ok = src.lookup(name)
+	if !s.secret {
+		return v, name, ok, nil
+	}
+	path, fromFile := src.lookup(name + src.fileSuffix)
+	if !fromFile {
+		return v, name, ok, nil
+	}
+	if ok {
+		return "", name, false, fmt.Errorf("set only one of %s and %s", name, name+src.fileSuffix)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", name, false, fmt.Errorf("%s: %w", name+src.fileSuffix, err)
+	}
+	return strings.TrimSpace(string(raw)), name + src.fileSuffix, true, nil
+}
+
+// An empty variable counts as unset, as it always has.
+func envSource() source {
+	return source{
+		name:       func(s setting) string { return s.env },
+		fileSuffix: "_FILE",
+		lookup: func(name string) (string, bool) {
+			v := os.Getenv(name)
+			return v, v != ""
+		},
+	}
+}
+
+func flagSource(values map[string]string) source {
+	return source{
+		name:       func(s setting) string { return "--" + s.flag() },
+		fileSuffix: "-file",
+		lookup: func(name string) (string, bool) {
+			v, ok := values[name]
+			return v, ok
+		},
+	}
+}
+
+// fileSource reads a YAML or TOML config file. Nested tables map to dotted
+// keys, so
+//
+//	llm:
+//	  timeout: 15s
+//
+// sets llm.timeout. Lists are joined with commas and tables written as
+// key=value pairs, with a nested table as the value written as
+// name:value;... (see llm.model_params), the same form the environment
+// uses.
+//
+// It also returns the keys that name no setting, sorted.
+func fileSource(path string) (source, []string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return source{}, nil, fmt.Errorf("read config file: %w", err)
+	}
+	doc := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, &doc)
+	case ".toml":
+		err = toml.Unmarshal(raw, &doc)
+	default:
+		return source{}, nil, fmt.Errorf("config file %s: unsupported format %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return source{}, nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", doc, values)
+	var unknown []string
+	for key := range values {
+		if !knownFileKey(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+
+	return source{
+		name:       func(s setting) string { return s.key },
+		fileSuffix: "_file",
+		lookup: func(name string) (string, bool) {
+			v, ok := values[name]
+			return v, ok
+		},
+	}, unknown, nil
+}
+
+func flatten(prefix string, node map[string]any, out map[string]string) {
+	for k, v := range node {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok && !isTable(key) {
+			flatten(key, m, out)
+			continue
+		}
+		out[key] = scalar(v)
+	}
+}
+
+func scalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = scalar(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		pairs := make([]string, 0, len(v))
+		for k, item := range v {
+			if inner, ok := item.(map[string]any); ok {
+				pairs = append(pairs, k+"="+innerTable(inner))
+				continue
+			}
+			pairs = append(pairs, k+"="+scalar(item))
+		}
+		slices.Sort(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func innerTable(m map[string]any) string {
+	pairs := make([]string, 0, len(m))
+	for k, item := range m {
+		pairs = append(pairs, k+":"+scalar(item))
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ";")
+}
+
+func isTable(key string) bool {
+	return slices.ContainsFunc(settings, func(s setting) bool { return s.table && s.key == key })
+}
+
+func knownFileKey(key string) bool {
+	return slices.ContainsFunc(settings, func(s setting) bool {
+		return s.key == key || s.secret && s.key+"_file" == key
+	})
+}
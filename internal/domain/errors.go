@@ -3,9 +3,22 @@ package domain
 import "errors"
 
 var (
-	ErrInvalidN      = errors.New("n must be between 1 and 10")
-	ErrNExceedsDeck  = errors.New("n exceeds number of cards in deck")
-	ErrDeckNotFound  = errors.New("deck not found")
-	ErrUpstreamLLM   = errors.New("upstream LLM failure")
+	ErrInvalidN       = errors.New("n must be between 1 and 10")
+	ErrNExceedsDeck   = errors.New("n exceeds number of cards in deck")
+	ErrDeckNotFound   = errors.New("deck not found")
+	ErrUnknownStyle   = errors.New("unknown interpretation style")
+	ErrInvalidLength  = errors.New("length must be short, medium or long")
+	ErrUpstreamLLM    = errors.New("upstream LLM failure")
 	ErrInvalidLLMJSON = errors.New("LLM returned invalid JSON after retry")
+	ErrBudgetExceeded = errors.New("LLM spending budget exceeded")
+	ErrRateLimited    = errors.New("rate limit exceeded")
+
+	ErrReadingNotFound  = errors.New("reading not found")
+	ErrFollowUpLimit    = errors.New("follow-up limit reached for this reading")
+	ErrQuestionRequired = errors.New("question is required")
+
+	ErrInvalidPosition       = errors.New("position is not part of the spread")
+	ErrInvalidClarifierCount = errors.New("clarifier count must be between 1 and 3")
+	ErrNoCardsLeft           = errors.New("not enough unused cards left in the deck")
+	ErrTableChanged          = errors.New("other cards were drawn for this reading meanwhile; try again")
 )
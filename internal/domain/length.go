@@ -0,0 +1,40 @@
+package domain
+
+import "strings"
+
+// Length is how long an interpretation the caller asked for. The empty
+// Length leaves the persona's usual length and the configured limits.
+type Length string
+
+const (
+	LengthShort  Length = "short"
+	LengthMedium Length = "medium"
+	LengthLong   Length = "long"
+)
+
+// Lengths lists the selectable lengths, shortest first.
+var Lengths = []Length{LengthShort, LengthMedium, LengthLong}
+
+// ParseLength resolves a length name (case-insensitive); empty is allowed.
+func ParseLength(name string) (Length, error) {
+	l := Length(strings.ToLower(strings.TrimSpace(name)))
+	switch l {
+	case "", LengthShort, LengthMedium, LengthLong:
+		return l, nil
+	default:
+		return "", ErrInvalidLength
+	}
+}
+
+// Words scales a persona's word limit to the length: short halves it and
+// long doubles it.
+func (l Length) Words(maxWords int) int {
+	switch l {
+	case LengthShort:
+		return maxWords / 2
+	case LengthLong:
+		return maxWords * 2
+	default:
+		return maxWords
+	}
+}
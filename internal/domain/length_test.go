@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+)
+
+func TestParseLength(t *testing.T) {
+	cases := map[string]domain.Length{
+		"":       "",
+		"short":  domain.LengthShort,
+		" Long ": domain.LengthLong,
+		"medium": domain.LengthMedium,
+	}
+	for name, want := range cases {
+		got, err := domain.ParseLength(name)
+		if err != nil || got != want {
+			t.Errorf("%q: got %q, %v", name, got, err)
+		}
+	}
+
+	if _, err := domain.ParseLength("epic"); err != domain.ErrInvalidLength {
+		t.Errorf("expected ErrInvalidLength, got %v", err)
+	}
+}
+
+func TestLength_Words(t *testing.T) {
+	for l, want := range map[domain.Length]int{"": 300, domain.LengthShort: 150, domain.LengthMedium: 300, domain.LengthLong: 600} {
+		if got := l.Words(300); got != want {
+			t.Errorf("%q: Words(300) = %d, want %d", l, got, want)
+		}
+	}
+}
@@ -29,6 +29,13 @@ type DrawnCard struct {
 	Orientation Orientation `json:"orientation"`
 }
 
+// Clarifier is an extra card drawn to shed light on one position of a
+// spread. Its Position continues the spread's numbering.
+type Clarifier struct {
+	DrawnCard
+	ForPosition int `json:"for_position"`
+}
+
 // Deck is a collection of tarot cards.
 type Deck struct {
 	ID    string `json:"id"`
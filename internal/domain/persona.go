@@ -0,0 +1,61 @@
+package domain
+
+import "strings"
+
+// Style identifies a reader persona.
+type Style string
+
+const (
+	StyleNeutral Style = "neutral"
+	StyleWarm    Style = "warm"
+	StylePoetic  Style = "poetic"
+	StyleJungian Style = "jungian"
+	StyleConcise Style = "concise"
+	StylePlayful Style = "playful"
+	DefaultStyle       = StyleNeutral
+)
+
+// Persona describes a selectable interpretation style. The prompt fragment
+// for each persona lives with the prompt templates; MaxWords bounds the
+// length of the interpretation it asks for.
+type Persona struct {
+	Style       Style  `json:"style"`
+	Description string `json:"description"`
+	MaxWords    int    `json:"max_words"`
+}
+
+var personas = []Persona{
+	{StyleNeutral, "Balanced and even-handed, no emotional colouring.", 300},
+	{StyleWarm, "Gentle and encouraging, like a supportive friend.", 300},
+	{StylePoetic, "Lyrical, image-rich language and metaphor.", 220},
+	{StyleJungian, "Psychological reading through archetypes, shadow and projection.", 350},
+	{StyleConcise, "Short and direct: the essentials only.", 80},
+	{StylePlayful, "Light-hearted and witty without mocking the question.", 250},
+}
+
+var styleAliases = map[string]Style{
+	"psychological": StyleJungian,
+}
+
+// Personas returns all registered personas in display order.
+func Personas() []Persona {
+	return append([]Persona(nil), personas...)
+}
+
+// LookupPersona resolves a style name (case-insensitive, aliases allowed).
+// An empty name selects DefaultStyle.
+func LookupPersona(name string) (Persona, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = string(DefaultStyle)
+	}
+	if alias, ok := styleAliases[name]; ok {
+		name = string(alias)
+	}
+	for _, p := range personas {
+		if string(p.Style) == name {
+			return p, nil
+		}
+	}
+	return Persona{}, ErrUnknownStyle
+}
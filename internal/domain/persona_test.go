@@ -0,0 +1,30 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+)
+
+func TestLookupPersona(t *testing.T) {
+	cases := map[string]domain.Style{
+		"":              domain.StyleNeutral,
+		"warm":          domain.StyleWarm,
+		" Poetic ":      domain.StylePoetic,
+		"psychological": domain.StyleJungian,
+	}
+	for name, want := range cases {
+		p, err := domain.LookupPersona(name)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", name, err)
+			continue
+		}
+		if p.Style != want {
+			t.Errorf("%q: expected %s, got %s", name, want, p.Style)
+		}
+	}
+
+	if _, err := domain.LookupPersona("sarcastic"); err != domain.ErrUnknownStyle {
+		t.Errorf("expected ErrUnknownStyle, got %v", err)
+	}
+}
@@ -10,8 +10,81 @@ func GenerateSpread(deck Deck, n int, spreadType SpreadType, rng RNG) (Spread, e
 		return Spread{}, ErrNExceedsDeck
 	}
 
+	return Spread{
+		Type:  spreadType,
+		Cards: draw(deck.Cards, n, 1, rng),
+	}, nil
+}
+
+// MaxClarifiers bounds how many clarifiers can be drawn at once.
+const MaxClarifiers = 3
+
+// DrawClarifiers draws n clarifiers for the spread card at forPosition.
+// Cards already on the table, in the spread or drawn as earlier
+// clarifiers, are excluded; orientation follows the same rule as the
+// spread. Clarifier positions continue the table's numbering.
+func DrawClarifiers(deck Deck, spread []DrawnCard, existing []Clarifier, forPosition, n int, rng RNG) ([]Clarifier, error) {
+	if n < 1 || n > MaxClarifiers {
+		return nil, ErrInvalidClarifierCount
+	}
+
+	used := make(map[string]bool, len(spread)+len(existing))
+	last, found := 0, false
+	for _, c := range spread {
+		used[c.ID] = true
+		last = max(last, c.Position)
+		found = found || c.Position == forPosition
+	}
+	if !found {
+		return nil, ErrInvalidPosition
+	}
+	for _, c := range existing {
+		used[c.ID] = true
+		last = max(last, c.Position)
+	}
+
+	available := make([]Card, 0, len(deck.Cards))
+	for _, c := range deck.Cards {
+		if !used[c.ID] {
+			available = append(available, c)
+		}
+	}
+	if n > len(available) {
+		return nil, ErrNoCardsLeft
+	}
+
+	drawn := draw(available, n, last+1, rng)
+	out := make([]Clarifier, n)
+	for i, dc := range drawn {
+		out[i] = Clarifier{DrawnCard: dc, ForPosition: forPosition}
+	}
+	return out, nil
+}
+
+// CheckClarifiers reports ErrTableChanged if a card or position of the
+// clarifiers in drawn is already on the table, e.g. taken by a concurrent
+// draw for the same reading since DrawClarifiers returned them.
+func CheckClarifiers(spread []DrawnCard, existing, drawn []Clarifier) error {
+	cards := make(map[string]bool, len(spread)+len(existing))
+	positions := make(map[int]bool, len(spread)+len(existing))
+	for _, c := range spread {
+		cards[c.ID], positions[c.Position] = true, true
+	}
+	for _, c := range existing {
+		cards[c.ID], positions[c.Position] = true, true
+	}
+	for _, c := range drawn {
+		if cards[c.ID] || positions[c.Position] {
+			return ErrTableChanged
+		}
+	}
+	return nil
+}
+
+// draw picks n unique cards from cards, numbering positions from first.
+func draw(cards []Card, n, first int, rng RNG) []DrawnCard {
 	// Fisher-Yates partial shuffle: only need first n elements.
-	indices := make([]int, len(deck.Cards))
+	indices := make([]int, len(cards))
 	for i := range indices {
 		indices[i] = i
 	}
@@ -20,21 +93,21 @@ func GenerateSpread(deck Deck, n int, spreadType SpreadType, rng RNG) (Spread, e
 		indices[i], indices[j] = indices[j], indices[i]
 	}
 
-	cards := make([]DrawnCard, n)
+	drawn := make([]DrawnCard, n)
 	for i := range n {
-		orientation := Upright
-		if rng.Intn(2) == 1 {
-			orientation = Reversed
-		}
-		cards[i] = DrawnCard{
-			Card:        deck.Cards[indices[i]],
-			Position:    i + 1,
-			Orientation: orientation,
+		drawn[i] = DrawnCard{
+			Card:        cards[indices[i]],
+			Position:    first + i,
+			Orientation: orientation(rng),
 		}
 	}
+	return drawn
+}
 
-	return Spread{
-		Type:  spreadType,
-		Cards: cards,
-	}, nil
+// orientation is the reversal policy shared by spreads and clarifiers.
+func orientation(rng RNG) Orientation {
+	if rng.Intn(2) == 1 {
+		return Reversed
+	}
+	return Upright
 }
@@ -120,3 +120,78 @@ func TestGenerateSpread_NExceedsDeck(t *testing.T) {
 		t.Errorf("expected ErrNExceedsDeck, got %v", err)
 	}
 }
+
+func TestDrawClarifiers_ExcludesCardsOnTable(t *testing.T) {
+	deck := testDeck(5)
+	spread := []domain.DrawnCard{
+		{Card: deck.Cards[0], Position: 1},
+		{Card: deck.Cards[1], Position: 2},
+	}
+	existing := []domain.Clarifier{
+		{DrawnCard: domain.DrawnCard{Card: deck.Cards[2], Position: 3}, ForPosition: 1},
+	}
+	rng := &deterministicRNG{values: []int{
+		0, // shuffle (1 swap for the 2 unused cards)
+		1, // orientation: reversed
+	}}
+
+	got, err := domain.DrawClarifiers(deck, spread, existing, 2, 1, rng)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 clarifier, got %d", len(got))
+	}
+	c := got[0]
+	if c.ID != deck.Cards[3].ID && c.ID != deck.Cards[4].ID {
+		t.Errorf("clarifier reused a card on the table: %s", c.ID)
+	}
+	if c.Position != 4 || c.ForPosition != 2 || c.Orientation != domain.Reversed {
+		t.Errorf("unexpected clarifier: %+v", c)
+	}
+}
+
+func TestDrawClarifiers_Errors(t *testing.T) {
+	deck := testDeck(3)
+	spread := []domain.DrawnCard{
+		{Card: deck.Cards[0], Position: 1},
+		{Card: deck.Cards[1], Position: 2},
+	}
+	rng := &deterministicRNG{values: []int{0}}
+
+	cases := []struct {
+		name        string
+		forPosition int
+		n           int
+		want        error
+	}{
+		{"unknown position", 5, 1, domain.ErrInvalidPosition},
+		{"zero count", 1, 0, domain.ErrInvalidClarifierCount},
+		{"too many", 1, domain.MaxClarifiers + 1, domain.ErrInvalidClarifierCount},
+		{"deck exhausted", 1, 2, domain.ErrNoCardsLeft},
+	}
+	for _, tc := range cases {
+		if _, err := domain.DrawClarifiers(deck, spread, nil, tc.forPosition, tc.n, rng); err != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestCheckClarifiers(t *testing.T) {
+	deck := testDeck(5)
+	spread := []domain.DrawnCard{{Card: deck.Cards[0], Position: 1}}
+	existing := []domain.Clarifier{{DrawnCard: domain.DrawnCard{Card: deck.Cards[1], Position: 2}, ForPosition: 1}}
+	clarifier := func(card, position int) []domain.Clarifier {
+		return []domain.Clarifier{{DrawnCard: domain.DrawnCard{Card: deck.Cards[card], Position: position}, ForPosition: 1}}
+	}
+
+	if err := domain.CheckClarifiers(spread, existing, clarifier(2, 3)); err != nil {
+		t.Errorf("unused card and position: %v", err)
+	}
+	if err := domain.CheckClarifiers(spread, existing, clarifier(1, 3)); err != domain.ErrTableChanged {
+		t.Errorf("card already drawn: expected ErrTableChanged, got %v", err)
+	}
+	if err := domain.CheckClarifiers(spread, existing, clarifier(2, 2)); err != domain.ErrTableChanged {
+		t.Errorf("position already taken: expected ErrTableChanged, got %v", err)
+	}
+}
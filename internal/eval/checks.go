@@ -0,0 +1,183 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/llmjson"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/guardrails"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Sample is one candidate's interpretation of one case.
+type Sample struct {
+	Case   Case
+	Cards  []domain.DrawnCard
+	Output ports.InterpretOutput
+}
+
+// Outcome is the verdict of a check on a sample. A skipped check does not
+// count towards the score.
+type Outcome struct {
+	Pass    bool
+	Skipped bool
+	Detail  string // why the check failed or was skipped
+}
+
+// Check is an automatic quality check.
+type Check struct {
+	Name string
+	Run  func(Sample) Outcome
+}
+
+// Check names, as they appear in reports.
+const (
+	CheckSchema    = "schema"
+	CheckCards     = "cards"
+	CheckLanguage  = "language"
+	CheckForbidden = "forbidden"
+	CheckLength    = "length"
+)
+
+// minLanguageShare is the share of letters that must be in the requested
+// language's script.
+const minLanguageShare = 0.8
+
+// DefaultChecks returns every check, in report order.
+func DefaultChecks() []Check {
+	scanner := guardrails.NewScanner(guardrails.DefaultForbidden())
+	return []Check{
+		{CheckSchema, checkSchema},
+		{CheckCards, checkCards},
+		{CheckLanguage, checkLanguage},
+		{CheckForbidden, func(s Sample) Outcome {
+			if flags := scanner.Scan(s.Output); len(flags) > 0 {
+				return fail("found %s", strings.Join(flags, ", "))
+			}
+			return pass()
+		}},
+		{CheckLength, checkLength},
+	}
+}
+
+func pass() Outcome { return Outcome{Pass: true} }
+
+func fail(format string, args ...any) Outcome {
+	return Outcome{Detail: fmt.Sprintf(format, args...)}
+}
+
+func skip(reason string) Outcome {
+	return Outcome{Skipped: true, Detail: reason}
+}
+
+// checkSchema validates the interpretation the way the LLM adapters
+// validate a response: the request's JSON Schema and one note per drawn
+// position.
+func checkSchema(s Sample) Outcome {
+	raw, err := json.Marshal(s.Output)
+	if err != nil {
+		return fail("marshal: %v", err)
+	}
+	in := ports.InterpretInput{Style: s.Case.Style, Cards: make([]ports.CardInput, len(s.Cards))}
+	for i, c := range s.Cards {
+		in.Cards[i] = ports.CardInput{Name: c.Name, Position: c.Position}
+	}
+	if _, err := llmjson.Parse(string(raw), in, ""); err != nil {
+		return fail("%s", strings.Join(llmjson.Problems(err), "; "))
+	}
+	return pass()
+}
+
+// checkCards requires every drawn card to be named in the text or in its
+// own note. Card names are only known in English.
+func checkCards(s Sample) Outcome {
+	if baseLang(s.Case.Lang) != "en" {
+		return skip("card names are only checked in English")
+	}
+	notes := make(map[int]string, len(s.Output.Cards))
+	for _, c := range s.Output.Cards {
+		notes[c.Position] = strings.ToLower(c.Text)
+	}
+	text := strings.ToLower(s.Output.Text)
+	var missing []string
+	for _, c := range s.Cards {
+		name := strings.ToLower(c.Name)
+		if !strings.Contains(text, name) && !strings.Contains(notes[c.Position], name) {
+			missing = append(missing, c.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fail("does not mention %s", strings.Join(missing, ", "))
+	}
+	return pass()
+}
+
+// scripts lists the writing systems of languages not written in Latin
+// script; any other language is expected in Latin.
+var scripts = map[string][]*unicode.RangeTable{
+	"ru": {unicode.Cyrillic},
+	"uk": {unicode.Cyrillic},
+	"bg": {unicode.Cyrillic},
+	"el": {unicode.Greek},
+	"ar": {unicode.Arabic},
+	"he": {unicode.Hebrew},
+	"hi": {unicode.Devanagari},
+	"zh": {unicode.Han},
+	"ja": {unicode.Hiragana, unicode.Katakana, unicode.Han},
+	"ko": {unicode.Hangul, unicode.Han},
+}
+
+// checkLanguage requires most letters of the text to be in the script of
+// the requested language. It tells Russian from English, not Spanish from
+// English.
+func checkLanguage(s Sample) Outcome {
+	want, ok := scripts[baseLang(s.Case.Lang)]
+	if !ok {
+		want = []*unicode.RangeTable{unicode.Latin}
+	}
+	letters, inScript := 0, 0
+	for _, r := range s.Output.Text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, want...) {
+			inScript++
+		}
+	}
+	if letters == 0 {
+		return fail("text has no letters")
+	}
+	if share := float64(inScript) / float64(letters); share < minLanguageShare {
+		return fail("%.0f%% of letters are in the script of %q, want at least %.0f%%", share*100, s.Case.Lang, minLanguageShare*100)
+	}
+	return pass()
+}
+
+// checkLength bounds the words in the text by the limit the prompt asks
+// for, the persona's scaled by the requested length, and by a tenth of it
+// from below.
+func checkLength(s Sample) Outcome {
+	switch baseLang(s.Case.Lang) {
+	case "zh", "ja":
+		return skip("words are not separated by spaces")
+	}
+	persona, err := domain.LookupPersona(s.Case.Style)
+	if err != nil {
+		return fail("%v", err)
+	}
+	limit := domain.Length(s.Case.Length).Words(persona.MaxWords)
+	words := len(strings.Fields(s.Output.Text))
+	if words > limit || words < limit/10 {
+		return fail("%d words, want %d to %d", words, limit/10, limit)
+	}
+	return pass()
+}
+
+func baseLang(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	return base
+}
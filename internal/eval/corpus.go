@@ -0,0 +1,113 @@
+// Package eval scores interpreters on a fixed corpus of spreads and
+// questions, so models and prompt templates can be compared on the same
+// readings instead of by feel. Every candidate sees the same cards: each
+// case draws them from its own seed.
+package eval
+
+import (
+	_ "embed"
+	"fmt"
+	"hash/fnv"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+)
+
+//go:embed corpus.yaml
+var defaultCorpus []byte
+
+// Case is one reading in the corpus. Its fields mirror the reading
+// parameters of the API.
+type Case struct {
+	ID       string `yaml:"id" json:"id"`
+	Question string `yaml:"q" json:"q,omitempty"`
+	N        int    `yaml:"n" json:"n"`
+	Deck     string `yaml:"deck" json:"deck"`
+	Spread   string `yaml:"spread" json:"spread"`
+	Lang     string `yaml:"lang" json:"lang"`
+	Style    string `yaml:"style" json:"style,omitempty"`
+	Length   string `yaml:"length" json:"length,omitempty"`
+	Seed     *int64 `yaml:"seed" json:"seed"` // nil derives one from ID
+}
+
+// Corpus is the list of cases every candidate is run on.
+type Corpus struct {
+	Cases []Case `yaml:"cases"`
+}
+
+// DefaultCorpus returns the embedded corpus.
+func DefaultCorpus() Corpus {
+	c, err := ParseCorpus(defaultCorpus)
+	if err != nil {
+		panic(fmt.Sprintf("eval: embedded corpus is invalid: %v", err))
+	}
+	return c
+}
+
+// LoadCorpus reads a YAML corpus file.
+func LoadCorpus(path string) (Corpus, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Corpus{}, fmt.Errorf("read corpus: %w", err)
+	}
+	c, err := ParseCorpus(raw)
+	if err != nil {
+		return Corpus{}, fmt.Errorf("corpus %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// ParseCorpus decodes a YAML corpus, fills in the API's defaults and
+// checks every case.
+func ParseCorpus(raw []byte) (Corpus, error) {
+	var c Corpus
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return Corpus{}, err
+	}
+	if len(c.Cases) == 0 {
+		return Corpus{}, fmt.Errorf("no cases")
+	}
+	seen := make(map[string]bool, len(c.Cases))
+	for i := range c.Cases {
+		tc := &c.Cases[i]
+		if tc.ID == "" {
+			return Corpus{}, fmt.Errorf("case %d has no id", i+1)
+		}
+		if seen[tc.ID] {
+			return Corpus{}, fmt.Errorf("case id %q is used twice", tc.ID)
+		}
+		seen[tc.ID] = true
+
+		if tc.N == 0 {
+			tc.N = 3
+		}
+		if tc.N < 1 || tc.N > 10 {
+			return Corpus{}, fmt.Errorf("case %q: %w", tc.ID, domain.ErrInvalidN)
+		}
+		if _, err := domain.LookupPersona(tc.Style); err != nil {
+			return Corpus{}, fmt.Errorf("case %q: %w", tc.ID, err)
+		}
+		if _, err := domain.ParseLength(tc.Length); err != nil {
+			return Corpus{}, fmt.Errorf("case %q: %w", tc.ID, err)
+		}
+		tc.Deck = orDefault(tc.Deck, "major_arcana")
+		tc.Spread = orDefault(tc.Spread, "generic")
+		tc.Lang = orDefault(tc.Lang, "en")
+		if tc.Seed == nil {
+			h := fnv.New64a()
+			h.Write([]byte(tc.ID))
+			seed := int64(h.Sum64() >> 1)
+			tc.Seed = &seed
+		}
+	}
+	return c, nil
+}
+
+func orDefault(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
@@ -0,0 +1,225 @@
+package eval_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/adapters/decks"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/template"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/eval"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// fakeInterpreter answers like a well-behaved model, in English.
+type fakeInterpreter struct {
+	inputs []ports.InterpretInput
+}
+
+func (f *fakeInterpreter) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	f.inputs = append(f.inputs, in)
+	out := ports.InterpretOutput{Summary: "s", Theme: "t", Questions: []string{}, Style: in.Style, Disclaimer: "d"}
+	var text []string
+	for _, c := range in.Cards {
+		out.Cards = append(out.Cards, ports.CardInterpretation{Position: c.Position, Text: c.Name + " invites reflection."})
+		text = append(text, "The card "+c.Name+" speaks of gentle change and patience in the days ahead.")
+	}
+	out.Text = strings.Join(text, " ")
+	out.Usage.TotalTokens = 100
+	return out, nil
+}
+
+type failingInterpreter struct{}
+
+func (failingInterpreter) Interpret(context.Context, ports.InterpretInput) (ports.InterpretOutput, error) {
+	return ports.InterpretOutput{}, errors.New("upstream down")
+}
+
+func TestParseCorpus(t *testing.T) {
+	c, err := eval.ParseCorpus([]byte(`
+cases:
+  - id: a
+  - id: b
+    n: 5
+    lang: ru
+    seed: 9
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := c.Cases[0]
+	if a.N != 3 || a.Deck != "major_arcana" || a.Spread != "generic" || a.Lang != "en" || a.Seed == nil {
+		t.Errorf("defaults not applied: %+v", a)
+	}
+	if *c.Cases[1].Seed != 9 {
+		t.Errorf("seed = %d", *c.Cases[1].Seed)
+	}
+
+	for raw, want := range map[string]string{
+		`cases: []`:                         "no cases",
+		`cases: [{id: a}, {id: a}]`:         `"a" is used twice`,
+		`cases: [{n: 3}]`:                   "has no id",
+		`cases: [{id: a, n: 11}]`:           domain.ErrInvalidN.Error(),
+		`cases: [{id: a, style: grumpy}]`:   domain.ErrUnknownStyle.Error(),
+		`cases: [{id: a, length: endless}]`: domain.ErrInvalidLength.Error(),
+	} {
+		if _, err := eval.ParseCorpus([]byte(raw)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want %q", raw, err, want)
+		}
+	}
+}
+
+func TestDefaultCorpus(t *testing.T) {
+	if c := eval.DefaultCorpus(); len(c.Cases) < 10 {
+		t.Errorf("default corpus has only %d cases", len(c.Cases))
+	}
+}
+
+func TestRun(t *testing.T) {
+	corpus, err := eval.ParseCorpus([]byte(`
+cases:
+  - id: three
+    q: What next?
+    style: warm
+  - id: russian
+    lang: ru
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := &fakeInterpreter{}
+	report := eval.Run(context.Background(), decks.NewEmbeddedStore(), corpus, []eval.Candidate{
+		{Name: "good", Interpreter: good},
+		{Name: "template", Interpreter: template.NewInterpreter()},
+		{Name: "down", Interpreter: failingInterpreter{}},
+	}, eval.DefaultChecks())
+
+	if report.Cases != 2 || len(report.Candidates) != 3 {
+		t.Fatalf("unexpected report shape: %+v", report)
+	}
+	goodReport, tmpl, down := report.Candidates[0], report.Candidates[1], report.Candidates[2]
+
+	// The fake answers in English, so only the Russian case's language
+	// check fails; card names are not checked in Russian.
+	three, russian := goodReport.Results[0], goodReport.Results[1]
+	if three.Score != 1 || three.Tokens != 100 || len(three.Cards) != 3 {
+		t.Errorf("three = %+v", three)
+	}
+	if russian.Score != 0.75 || !failed(russian, eval.CheckLanguage) || !skipped(russian, eval.CheckCards) {
+		t.Errorf("russian = %+v", russian)
+	}
+	if goodReport.Summary.PassRate[eval.CheckLanguage] != 0.5 || goodReport.Summary.PassRate[eval.CheckCards] != 1 {
+		t.Errorf("summary = %+v", goodReport.Summary)
+	}
+	if good.inputs[0].Seed == nil || good.inputs[0].Style != "warm" {
+		t.Errorf("case parameters not passed on: %+v", good.inputs[0])
+	}
+
+	// Every candidate interprets the same cards.
+	if !reflect.DeepEqual(tmpl.Results[0].Cards, three.Cards) {
+		t.Errorf("candidates drew different cards: %v, %v", tmpl.Results[0].Cards, three.Cards)
+	}
+	if !failed(tmpl.Results[0], eval.CheckSchema) {
+		t.Error("the template interpreter ignores the style, which the schema check should catch")
+	}
+
+	if down.Summary.Errors != 2 || down.Summary.Score != 0 || down.Results[0].Error == "" {
+		t.Errorf("down = %+v", down)
+	}
+
+	var md bytes.Buffer
+	if err := report.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| Candidate | Score | schema | cards | language | forbidden | length |",
+		"| good | 0.88 | 100% | 100% | 50% | 100% | 100% | 0 |",
+		"## Failures: good\n\n- `russian` language:",
+		"- `three` error: interpret: upstream down",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := report.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded eval.Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Candidates[0].Results[0].Output.Text != three.Output.Text {
+		t.Error("JSON report does not carry the interpretation")
+	}
+}
+
+func TestChecks(t *testing.T) {
+	checks := make(map[string]eval.Check)
+	for _, c := range eval.DefaultChecks() {
+		checks[c.Name] = c
+	}
+	cards := []domain.DrawnCard{{Card: domain.Card{Name: "The Star"}, Position: 1}}
+	sample := func(lang, text string) eval.Sample {
+		return eval.Sample{
+			Case:   eval.Case{Lang: lang},
+			Cards:  cards,
+			Output: ports.InterpretOutput{Text: text, Cards: []ports.CardInterpretation{{Position: 1, Text: "note"}}},
+		}
+	}
+	words := func(n int) string { return strings.TrimSpace(strings.Repeat("hope ", n)) }
+
+	for _, tc := range []struct {
+		check string
+		s     eval.Sample
+		pass  bool
+	}{
+		{eval.CheckLanguage, sample("ru", "Звезда приносит надежду."), true},
+		{eval.CheckLanguage, sample("ru-RU", "The Star brings hope."), false},
+		{eval.CheckLanguage, sample("es", "La Estrella trae esperanza."), true},
+		{eval.CheckForbidden, sample("en", "The Star guarantees success."), false},
+		{eval.CheckForbidden, sample("en", "The Star invites hope."), true},
+		{eval.CheckLength, sample("en", words(300)), true},
+		{eval.CheckLength, sample("en", words(301)), false},
+		{eval.CheckLength, sample("en", words(29)), false},
+		{eval.CheckCards, sample("en", "the star shines"), true},
+		{eval.CheckCards, sample("en", "a bright light"), false},
+	} {
+		if o := checks[tc.check].Run(tc.s); o.Pass != tc.pass || o.Skipped {
+			t.Errorf("%s on %q: %+v, want pass=%v", tc.check, tc.s.Output.Text, o, tc.pass)
+		}
+	}
+
+	short := sample("en", words(200))
+	short.Case.Length = "short"
+	if o := checks[eval.CheckLength].Run(short); o.Pass {
+		t.Error("200 words passed for a short reading")
+	}
+	if o := checks[eval.CheckLength].Run(sample("ja", "星")); !o.Skipped {
+		t.Errorf("length of Japanese text should be skipped: %+v", o)
+	}
+}
+
+func failed(r eval.Result, check string) bool {
+	for _, c := range r.Checks {
+		if c.Name == check {
+			return !c.Pass && !c.Skipped
+		}
+	}
+	return false
+}
+
+func skipped(r eval.Result, check string) bool {
+	for _, c := range r.Checks {
+		if c.Name == check {
+			return c.Skipped
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Report compares candidates over one corpus.
+type Report struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Cases       int               `json:"cases"`
+	Checks      []string          `json:"checks"`
+	Candidates  []CandidateReport `json:"candidates"`
+}
+
+// CandidateReport holds one candidate's results, in corpus order.
+type CandidateReport struct {
+	Name    string   `json:"name"`
+	Summary Summary  `json:"summary"`
+	Results []Result `json:"results"`
+}
+
+// Summary aggregates a candidate's results. Failed readings score 0 and
+// are left out of the latency and token figures.
+type Summary struct {
+	Score         float64            `json:"score"`     // mean case score
+	PassRate      map[string]float64 `json:"pass_rate"` // by check, over the cases it ran on
+	Errors        int                `json:"errors"`
+	MeanLatencyMS int64              `json:"mean_latency_ms"`
+	P95LatencyMS  int64              `json:"p95_latency_ms"`
+	TotalTokens   int                `json:"total_tokens"`
+	MeanTokens    int                `json:"mean_tokens"`
+}
+
+// Result is the outcome of one case.
+type Result struct {
+	Case      string                 `json:"case"`
+	Cards     []string               `json:"cards,omitempty"`
+	Model     string                 `json:"model,omitempty"`
+	LatencyMS int64                  `json:"latency_ms"`
+	Tokens    int                    `json:"tokens"`
+	Error     string                 `json:"error,omitempty"`
+	Score     float64                `json:"score"` // share of the checks that ran which passed
+	Checks    []CheckResult          `json:"checks,omitempty"`
+	Output    *ports.InterpretOutput `json:"output,omitempty"`
+}
+
+// CheckResult is one check's verdict on a result.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Pass    bool   `json:"pass"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+func summarize(results []Result, checks []string) Summary {
+	s := Summary{PassRate: make(map[string]float64, len(checks))}
+	ran := make(map[string]int, len(checks))
+	passed := make(map[string]int, len(checks))
+	var latencies []int64
+	for _, r := range results {
+		s.Score += r.Score
+		if r.Error != "" {
+			s.Errors++
+			continue
+		}
+		latencies = append(latencies, r.LatencyMS)
+		s.TotalTokens += r.Tokens
+		for _, c := range r.Checks {
+			if c.Skipped {
+				continue
+			}
+			ran[c.Name]++
+			if c.Pass {
+				passed[c.Name]++
+			}
+		}
+	}
+	if len(results) > 0 {
+		s.Score /= float64(len(results))
+	}
+	for _, name := range checks {
+		if ran[name] > 0 {
+			s.PassRate[name] = float64(passed[name]) / float64(ran[name])
+		}
+	}
+	if n := len(latencies); n > 0 {
+		var total int64
+		for _, l := range latencies {
+			total += l
+		}
+		s.MeanLatencyMS = total / int64(n)
+		slices.Sort(latencies)
+		s.P95LatencyMS = latencies[(n*95+99)/100-1]
+		s.MeanTokens = s.TotalTokens / n
+	}
+	return s
+}
+
+// WriteJSON writes the full report, including every interpretation.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes a comparison table followed by every failed check
+// and reading, for reviewing in a pull request.
+func (r Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Interpretation eval\n\n%d cases, generated %s.\n\n", r.Cases, r.GeneratedAt.Format(time.RFC3339))
+
+	b.WriteString("| Candidate | Score |")
+	for _, name := range r.Checks {
+		fmt.Fprintf(&b, " %s |", name)
+	}
+	b.WriteString(" Errors | Mean latency | p95 latency | Mean tokens |\n|---|---|")
+	b.WriteString(strings.Repeat("---|", len(r.Checks)+4) + "\n")
+	for _, c := range r.Candidates {
+		fmt.Fprintf(&b, "| %s | %.2f |", c.Name, c.Summary.Score)
+		for _, name := range r.Checks {
+			if rate, ok := c.Summary.PassRate[name]; ok {
+				fmt.Fprintf(&b, " %.0f%% |", rate*100)
+			} else {
+				b.WriteString(" – |")
+			}
+		}
+		fmt.Fprintf(&b, " %d | %d ms | %d ms | %d |\n", c.Summary.Errors, c.Summary.MeanLatencyMS, c.Summary.P95LatencyMS, c.Summary.MeanTokens)
+	}
+
+	for _, c := range r.Candidates {
+		var lines []string
+		for _, res := range c.Results {
+			if res.Error != "" {
+				lines = append(lines, fmt.Sprintf("- `%s` error: %s", res.Case, res.Error))
+				continue
+			}
+			for _, check := range res.Checks {
+				if !check.Pass && !check.Skipped {
+					lines = append(lines, fmt.Sprintf("- `%s` %s: %s", res.Case, check.Name, check.Detail))
+				}
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## Failures: %s\n\n%s\n", c.Name, strings.Join(lines, "\n"))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,97 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/app"
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Candidate is an interpreter configuration under evaluation, such as a
+// model with a prompt template set.
+type Candidate struct {
+	Name        string
+	Interpreter ports.Interpreter
+}
+
+// Run interprets every case of corpus with every candidate, one reading at
+// a time so latencies are comparable, and scores the results with checks.
+// A cancelled ctx stops the run; the report then holds what finished.
+func Run(ctx context.Context, decks ports.DeckStore, corpus Corpus, candidates []Candidate, checks []Check) Report {
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		Cases:       len(corpus.Cases),
+		Checks:      make([]string, len(checks)),
+	}
+	for i, c := range checks {
+		report.Checks[i] = c.Name
+	}
+
+	for _, cand := range candidates {
+		// Every case has a seed, so the service never uses its own RNG.
+		svc := app.NewTarotService(decks, cand.Interpreter, nil, "")
+		cr := CandidateReport{Name: cand.Name}
+		for _, tc := range corpus.Cases {
+			if ctx.Err() != nil {
+				break
+			}
+			cr.Results = append(cr.Results, runCase(ctx, svc, tc, checks))
+		}
+		cr.Summary = summarize(cr.Results, report.Checks)
+		report.Candidates = append(report.Candidates, cr)
+	}
+	return report
+}
+
+func runCase(ctx context.Context, svc *app.TarotService, tc Case, checks []Check) Result {
+	res := Result{Case: tc.ID}
+	resp, err := svc.ReadSpread(ctx, app.ReadSpreadRequest{
+		Question:   tc.Question,
+		NumCards:   tc.N,
+		DeckID:     tc.Deck,
+		SpreadType: tc.Spread,
+		Lang:       tc.Lang,
+		Style:      tc.Style,
+		Length:     tc.Length,
+		Seed:       tc.Seed,
+	})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	res.Cards = cardNames(resp.Cards)
+	res.Model = resp.Model
+	res.LatencyMS = resp.LatencyMS
+	res.Tokens = resp.Interpretation.Usage.TotalTokens
+	res.Output = &resp.Interpretation
+
+	sample := Sample{Case: tc, Cards: resp.Cards, Output: resp.Interpretation}
+	ran, passed := 0, 0
+	for _, c := range checks {
+		o := c.Run(sample)
+		res.Checks = append(res.Checks, CheckResult{Name: c.Name, Pass: o.Pass, Skipped: o.Skipped, Detail: o.Detail})
+		if o.Skipped {
+			continue
+		}
+		ran++
+		if o.Pass {
+			passed++
+		}
+	}
+	if ran > 0 {
+		res.Score = float64(passed) / float64(ran)
+	}
+	return res
+}
+
+func cardNames(cards []domain.DrawnCard) []string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = fmt.Sprintf("%d. %s (%s)", c.Position, c.Name, c.Orientation)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+// Package guardrails keeps readings away from harmful territory: it
+// classifies questions into sensitive categories before interpretation and
+// scans generated text for advice the service must never give.
+package guardrails
+
+import (
+	"context"
+	"regexp"
+)
+
+// Category is a sensitive topic detected in a question.
+type Category string
+
+const (
+	SelfHarm  Category = "self_harm"
+	Medical   Category = "medical"
+	Legal     Category = "legal"
+	Financial Category = "financial"
+	Minors    Category = "minors"
+)
+
+// Classifier detects sensitive categories in a question. Implementations
+// must be safe for concurrent use.
+type Classifier interface {
+	Classify(ctx context.Context, question string) []Category
+}
+
+// Rule flags Category when any of Patterns matches.
+type Rule struct {
+	Category Category
+	Patterns []*regexp.Regexp
+}
+
+// RuleClassifier is a Classifier driven by regular expressions.
+type RuleClassifier struct {
+	rules []Rule
+}
+
+func NewRuleClassifier(rules []Rule) *RuleClassifier {
+	return &RuleClassifier{rules: rules}
+}
+
+// Classify returns each matching category once, in rule order.
+func (c *RuleClassifier) Classify(_ context.Context, question string) []Category {
+	var out []Category
+	for _, r := range c.rules {
+		for _, p := range r.Patterns {
+			if p.MatchString(question) {
+				out = append(out, r.Category)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// DefaultRules are conservative English patterns for each category.
+// Self-harm comes first because it overrides everything else.
+func DefaultRules() []Rule {
+	return []Rule{
+		{SelfHarm, compile(
+			// First person only: "will he hurt me again?" is not about self-harm.
+			`\b(kill|hurt|harm|cut|starve)\s+myself\b`,
+			`\bi(\s+am|'m|m)?\s+(want|going|gonna|wanna|planning|ready)(\s+to)?\s+kill\s+me\b`,
+			`\bsuicid`,
+			`\bend (it all|my life)\b`,
+			`\b(want|wish) to die\b`,
+			`\bself[- ]?harm`,
+			`\bno reason to (live|go on)\b`,
+		)},
+		{Medical, compile(
+			`\b(diagnos|symptom|cancer|tumou?r|disease|illness|medicat|prescri|pregnan|surgery|chemo|depress|bipolar|adhd)\w*`,
+			`\b(doctor|hospital|therapist|biopsy|test results?)\b`,
+			`\bam i (sick|ill)\b`,
+		)},
+		{Legal, compile(
+			`\b(lawsuit|sue|suing|court|lawyer|attorney|custody|divorce|visa|immigration|arrest(ed)?|prosecut\w*|verdict|legal(ly)?)\b`,
+		)},
+		{Financial, compile(
+			`\b(invest\w*|stocks?|crypto\w*|bitcoin|loan|mortgage|debt|bankrupt\w*|lottery|gambl\w*|betting|trading|shares)\b`,
+		)},
+		{Minors, compile(
+			`\b(i am|i'm|im) (1[0-7]|[1-9])\b`,
+			`\b(1[0-7]|[1-9]) ?(years? old|yo|y/o)\b`,
+			`\bmy (son|daughter|child|kid|baby|toddler)\b`,
+			`\b(middle|elementary|primary) school\b`,
+		)},
+	}
+}
+
+func compile(patterns ...string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		out[i] = regexp.MustCompile(`(?i)` + p)
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package guardrails
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Actions recorded in ports.SafetyDecision.
+const (
+	ActionNone     = "none"
+	ActionAdapted  = "adapted"  // prompt carried extra guidance
+	ActionCanned   = "canned"   // supportive response returned without the LLM
+	ActionReplaced = "replaced" // LLM output failed the scan and was replaced
+)
+
+// guidance is added to the prompt for each category that does not need a
+// canned response.
+var guidance = map[Category]string{
+	Medical:   "The question touches on health. Do not interpret symptoms, name conditions or suggest treatments; gently encourage speaking with a qualified medical professional.",
+	Legal:     "The question touches on a legal matter. Do not predict legal outcomes or suggest legal actions; gently encourage consulting a qualified lawyer.",
+	Financial: "The question touches on money. Do not predict market movements or recommend financial decisions; gently encourage consulting a qualified financial adviser.",
+	Minors:    "The querent may be a minor or the question concerns a child. Keep the reading age-appropriate, gentle and free of adult themes, and encourage talking with a trusted adult.",
+}
+
+const (
+	supportiveText = "It sounds like you may be going through something really painful right now. " +
+		"The cards can wait; you deserve support from a person. " +
+		"If you are in immediate danger, please call your local emergency number. " +
+		"You can find a free, confidential helpline in your country at https://findahelpline.com " +
+		"(in the US, call or text 988; in the UK and Ireland, call Samaritans on 116 123). " +
+		"Reaching out to someone you trust can also help."
+	supportiveDisclaimer = "This service cannot help in a crisis. Please contact a helpline or emergency services."
+)
+
+// Guard implements ports.Interpreter around another interpreter, applying
+// the guardrails on the way in and out. Questions about self-harm get a
+// supportive response with resources instead of a reading; other sensitive
+// categories and suspected prompt injection add guidance to the prompt.
+// Output containing a forbidden pattern is replaced with the fallback
+// interpreter's reading.
+type Guard struct {
+	classifier Classifier
+	scanner    *Scanner
+	next       ports.Interpreter
+	fallback   ports.Interpreter
+	logger     *slog.Logger
+}
+
+func NewGuard(classifier Classifier, scanner *Scanner, next, fallback ports.Interpreter, logger *slog.Logger) *Guard {
+	return &Guard{
+		classifier: classifier,
+		scanner:    scanner,
+		next:       next,
+		fallback:   fallback,
+		logger:     logger,
+	}
+}
+
+func (g *Guard) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	categories := g.classifier.Classify(ctx, in.Question)
+	decision := ports.SafetyDecision{Categories: categoryNames(categories), Action: ActionNone}
+
+	if slices.Contains(categories, SelfHarm) {
+		decision.Action = ActionCanned
+		g.logger.WarnContext(ctx, "safety: returning supportive response", "categories", decision.Categories)
+		return ports.InterpretOutput{
+			Text:       supportiveText,
+			Style:      "supportive",
+			Disclaimer: supportiveDisclaimer,
+			Model:      "guardrails",
+			Safety:     decision,
+		}, nil
+	}
+
+	for _, c := range categories {
+		if text, ok := guidance[c]; ok {
+			in.Guidance = append(in.Guidance, text)
+			decision.Action = ActionAdapted
+		}
+	}
+	if flags := DetectInjection(in.Question); len(flags) > 0 {
+		g.logger.WarnContext(ctx, "safety: possible prompt injection in question", "flags", flags)
+		in.Guidance = append(in.Guidance, injectionGuidance)
+		decision.Action = ActionAdapted
+		decision.Flags = append(decision.Flags, flags...)
+	}
+
+	out, err := g.next.Interpret(ctx, in)
+	if err != nil {
+		return ports.InterpretOutput{}, err
+	}
+
+	if flags := g.scanner.Scan(out); len(flags) > 0 {
+		g.logger.WarnContext(ctx, "safety: interpretation failed output scan, replacing",
+			"model", out.Model, "flags", flags)
+		replacement, err := g.fallback.Interpret(ctx, in)
+		if err != nil {
+			return ports.InterpretOutput{}, err
+		}
+		// Tokens were still spent on the rejected output.
+		replacement.Usage = out.Usage
+		out = replacement
+		decision.Action = ActionReplaced
+		decision.Flags = append(decision.Flags, flags...)
+	}
+
+	out.Safety = decision
+	return out, nil
+}
+
+func categoryNames(cs []Category) []string {
+	if len(cs) == 0 {
+		return nil
+	}
+	out := make([]string, len(cs))
+	for i, c := range cs {
+		out[i] = string(c)
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package guardrails_test
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/guardrails"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+type recordingInterpreter struct {
+	out   ports.InterpretOutput
+	got   ports.InterpretInput
+	calls int
+}
+
+func (r *recordingInterpreter) Interpret(_ context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	r.calls++
+	r.got = in
+	return r.out, nil
+}
+
+func newGuard(next, fallback ports.Interpreter) *guardrails.Guard {
+	return guardrails.NewGuard(
+		guardrails.NewRuleClassifier(guardrails.DefaultRules()),
+		guardrails.NewScanner(guardrails.DefaultForbidden()),
+		next, fallback, slog.Default(),
+	)
+}
+
+func TestRuleClassifier(t *testing.T) {
+	c := guardrails.NewRuleClassifier(guardrails.DefaultRules())
+	cases := map[string][]guardrails.Category{
+		"What should I focus on today?":            nil,
+		"I want to end it all":                     {guardrails.SelfHarm},
+		"Sometimes I want to hurt myself":          {guardrails.SelfHarm},
+		"I'm going to kill me if nothing changes":  {guardrails.SelfHarm},
+		"Will he hurt me again?":                   nil,
+		"Is she going to cut me out of her life?":  nil,
+		"My boss will kill me if I am late":        nil,
+		"Will my biopsy come back clear?":          {guardrails.Medical},
+		"Should I sue my landlord?":                {guardrails.Legal},
+		"Is it time to buy bitcoin?":               {guardrails.Financial},
+		"I'm 14 and my crush ignores me":           {guardrails.Minors},
+		"Will the court grant custody of my son?":  {guardrails.Legal, guardrails.Minors},
+		"My doctor says I need surgery, any debt?": {guardrails.Medical, guardrails.Financial},
+	}
+	for q, want := range cases {
+		got := c.Classify(context.Background(), q)
+		if !slices.Equal(got, want) {
+			t.Errorf("Classify(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+func TestGuard_SelfHarmCanned(t *testing.T) {
+	next := &recordingInterpreter{}
+	g := newGuard(next, &recordingInterpreter{})
+
+	out, err := g.Interpret(context.Background(), ports.InterpretInput{Question: "I don't want to live, I want to die"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.calls != 0 {
+		t.Error("LLM must not be called for self-harm questions")
+	}
+	if out.Safety.Action != guardrails.ActionCanned {
+		t.Errorf("expected canned action, got %q", out.Safety.Action)
+	}
+	if !slices.Contains(out.Safety.Categories, "self_harm") {
+		t.Errorf("expected self_harm category, got %v", out.Safety.Categories)
+	}
+}
+
+func TestGuard_AdaptsPrompt(t *testing.T) {
+	next := &recordingInterpreter{out: ports.InterpretOutput{Text: "Consider the Wheel of Fortune's lessons on timing."}}
+	g := newGuard(next, &recordingInterpreter{})
+
+	out, err := g.Interpret(context.Background(), ports.InterpretInput{Question: "Should I invest my savings in stocks?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.got.Guidance) != 1 {
+		t.Fatalf("expected one guidance line for financial question, got %v", next.got.Guidance)
+	}
+	if out.Safety.Action != guardrails.ActionAdapted {
+		t.Errorf("expected adapted action, got %q", out.Safety.Action)
+	}
+}
+
+func TestGuard_ReplacesForbiddenOutput(t *testing.T) {
+	next := &recordingInterpreter{out: ports.InterpretOutput{
+		Text:  "The cards are clear.",
+		Cards: []ports.CardInterpretation{{Position: 1, Text: "You will definitely get the job."}},
+		Model: "llm",
+		Usage: ports.Usage{TotalTokens: 42},
+	}}
+	fallback := &recordingInterpreter{out: ports.InterpretOutput{Text: "A safe reading.", Model: "template"}}
+	g := newGuard(next, fallback)
+
+	out, err := g.Interpret(context.Background(), ports.InterpretInput{Question: "Will I get the job?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Text != "A safe reading." || out.Model != "template" {
+		t.Errorf("expected fallback output, got %+v", out)
+	}
+	if out.Safety.Action != guardrails.ActionReplaced || !slices.Contains(out.Safety.Flags, "guaranteed_outcome") {
+		t.Errorf("unexpected safety decision: %+v", out.Safety)
+	}
+	if out.Usage.TotalTokens != 42 {
+		t.Errorf("usage of the rejected output should be kept, got %+v", out.Usage)
+	}
+}
+
+func TestScanner_GuaranteedOutcome(t *testing.T) {
+	s := guardrails.NewScanner(guardrails.DefaultForbidden())
+	for _, text := range []string{
+		"I am 100% sure you will get the job.",
+		"Success is 100 %.",
+		"Your chances: 100%",
+		"This is guaranteed.",
+	} {
+		if flags := s.Scan(ports.InterpretOutput{Text: text}); !slices.Contains(flags, "guaranteed_outcome") {
+			t.Errorf("Scan(%q) = %v, want guaranteed_outcome", text, flags)
+		}
+	}
+	if flags := s.Scan(ports.InterpretOutput{Text: "Give 100 percent of your attention to what you can change."}); len(flags) != 0 {
+		t.Errorf("expected no flags, got %v", flags)
+	}
+}
+
+func TestScanner_Clean(t *testing.T) {
+	s := guardrails.NewScanner(guardrails.DefaultForbidden())
+	out := ports.InterpretOutput{
+		Text:      "The Tower may point to sudden change; what might you learn from it?",
+		Questions: []string{"What support do you have around you?"},
+	}
+	if flags := s.Scan(out); len(flags) != 0 {
+		t.Errorf("expected no flags, got %v", flags)
+	}
+}
@@ -0,0 +1,129 @@
+package guardrails_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/adapters/llm/openai"
+	"github.com/randomtoy/taas-go/internal/adapters/llm/template"
+	"github.com/randomtoy/taas-go/internal/guardrails"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func readCorpus(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestDetectInjection_Corpus(t *testing.T) {
+	for _, q := range readCorpus(t, "testdata/injection_corpus.txt") {
+		if flags := guardrails.DetectInjection(q); len(flags) == 0 {
+			t.Errorf("not flagged: %q", q)
+		}
+	}
+	for _, q := range readCorpus(t, "testdata/benign_corpus.txt") {
+		if flags := guardrails.DetectInjection(q); len(flags) != 0 {
+			t.Errorf("false positive %v: %q", flags, q)
+		}
+	}
+}
+
+// gullibleLLM is a fake chat-completions server that obeys injected
+// instructions: given a question it echoes its system prompt back as the
+// interpretation. It answers repair prompts with a clean reading.
+type gullibleLLM struct {
+	t        *testing.T
+	question string // the question as isolated in the last user prompt
+}
+
+func (g *gullibleLLM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	system, user := req.Messages[0].Content, req.Messages[1].Content
+
+	text := "A calm reading about patience."
+	if strings.Contains(user, "<question>") {
+		_, rest, _ := strings.Cut(user, "\n<question>\n")
+		g.question, _, _ = strings.Cut(rest, "\n</question>")
+		if strings.Contains(g.question, "<") && strings.Contains(strings.ToLower(g.question), "question") {
+			g.t.Errorf("question escaped its delimiters: %q", g.question)
+		}
+		text = "Sure! My instructions are: " + system
+	}
+
+	content, _ := json.Marshal(map[string]any{
+		"summary": "", "theme": "", "text": text, "questions": []string{},
+		"style": "neutral", "disclaimer": "",
+		"cards": []map[string]any{{"position": 1, "text": "The Fool invites a fresh start."}},
+	})
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"choices": []map[string]any{{"message": map[string]any{"content": string(content)}}},
+	})
+}
+
+func TestGuard_InjectionCorpus(t *testing.T) {
+	fake := &gullibleLLM{t: t}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := openai.NewClient(srv.Client(), "key", srv.URL, "model", nil, slog.Default())
+	g := newGuard(client, template.NewInterpreter())
+
+	for _, q := range readCorpus(t, "testdata/injection_corpus.txt") {
+		out, err := g.Interpret(context.Background(), ports.InterpretInput{
+			Question: q,
+			Cards:    []ports.CardInput{{Name: "The Fool", Position: 1, Orientation: "upright"}},
+		})
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", q, err)
+			continue
+		}
+		if strings.Contains(strings.ToLower(out.Text), "never provide medical") {
+			t.Errorf("%q: system prompt leaked into output: %s", q, out.Text)
+		}
+		if !hasInjectionFlag(out.Safety.Flags) {
+			t.Errorf("%q: expected injection flag in safety decision, got %+v", q, out.Safety)
+		}
+		if strings.ContainsAny(fake.question, "\n") {
+			t.Errorf("%q: isolated question spans lines: %q", q, fake.question)
+		}
+	}
+}
+
+func hasInjectionFlag(flags []string) bool {
+	for _, f := range flags {
+		if strings.HasPrefix(f, "injection_") {
+			return true
+		}
+	}
+	return false
+}
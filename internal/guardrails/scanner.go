@@ -0,0 +1,61 @@
+package guardrails
+
+import (
+	"regexp"
+
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Forbidden is a named pattern that must never appear in an interpretation.
+type Forbidden struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultForbidden catches diagnoses, guaranteed outcomes and direct
+// financial or legal instructions.
+func DefaultForbidden() []Forbidden {
+	return []Forbidden{
+		{"diagnosis", regexp.MustCompile(`(?i)\byou (have|are suffering from|suffer from|are showing signs of) (a |an )?\w*\s?(cancer|depression|disease|illness|disorder|tumou?r|infection|syndrome)\b`)},
+		{"diagnosis", regexp.MustCompile(`(?i)\b(you are|you're) (clinically )?(depressed|bipolar|pregnant|infertile)\b`)},
+		{"guaranteed_outcome", regexp.MustCompile(`(?i)\b(guarantee[sd]?|will (definitely|certainly|surely)|(definitely|certainly) will|is certain to|without (a|any) doubt)\b|\b100 ?%`)},
+		{"financial_directive", regexp.MustCompile(`(?i)\byou (should|must|need to) (buy|sell|invest|borrow|bet)\b`)},
+		{"legal_directive", regexp.MustCompile(`(?i)\byou (should|must|need to) (sue|plead|sign the|file for)\b`)},
+		{"medical_directive", regexp.MustCompile(`(?i)\byou (should|must|need to) (stop|start) (taking )?(your )?(medication|treatment|therapy)\b`)},
+	}
+}
+
+// Scanner reports forbidden patterns in generated text.
+type Scanner struct {
+	rules []Forbidden
+}
+
+func NewScanner(rules []Forbidden) *Scanner {
+	return &Scanner{rules: rules}
+}
+
+// Scan returns the names of all forbidden patterns found anywhere in out,
+// each at most once.
+func (s *Scanner) Scan(out ports.InterpretOutput) []string {
+	texts := []string{out.Text, out.Summary, out.Theme}
+	for _, c := range out.Cards {
+		texts = append(texts, c.Text)
+	}
+	texts = append(texts, out.Questions...)
+
+	var flags []string
+	seen := make(map[string]bool)
+	for _, r := range s.rules {
+		if seen[r.Name] {
+			continue
+		}
+		for _, t := range texts {
+			if r.Pattern.MatchString(t) {
+				flags = append(flags, r.Name)
+				seen[r.Name] = true
+				break
+			}
+		}
+	}
+	return flags
+}
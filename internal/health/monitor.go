@@ -0,0 +1,166 @@
+// Package health tracks whether the service's dependencies are usable so
+// that readiness probes can be answered without calling them on every
+// probe.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Checker reports whether a dependency is usable.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error { return f(ctx) }
+
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusFailing Status = "failing"
+	StatusUnknown Status = "unknown" // not checked yet
+)
+
+// Component is the outcome of the latest check of one dependency.
+type Component struct {
+	Status    Status     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	LatencyMS int64      `json:"latency_ms"`
+	Optional  bool       `json:"optional,omitempty"`
+}
+
+// Report is the readiness of the service: it is ready once every required
+// component's latest check succeeded, and degraded while an optional one
+// is not healthy.
+type Report struct {
+	Ready      bool                 `json:"ready"`
+	Degraded   bool                 `json:"degraded"`
+	Components map[string]Component `json:"components"`
+}
+
+// Monitor checks registered dependencies in the background every interval
+// and keeps the latest results.
+type Monitor struct {
+	mu       sync.Mutex
+	checks   []namedCheck
+	results  map[string]Component
+	interval time.Duration
+	timeout  time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+type namedCheck struct {
+	name     string
+	checker  Checker
+	optional bool
+}
+
+// NewMonitor returns a monitor that gives each check at most timeout.
+func NewMonitor(interval, timeout time.Duration, logger *slog.Logger) *Monitor {
+	return &Monitor{
+		results:  make(map[string]Component),
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Register adds a dependency the service cannot serve without. It must be
+// called before Run.
+func (m *Monitor) Register(name string, c Checker) {
+	m.register(namedCheck{name: name, checker: c})
+}
+
+// RegisterOptional adds a dependency whose failure marks the report
+// degraded but keeps the service ready, e.g. the LLM: every replica shares
+// the same upstream, so taking them out of rotation would not help. It must
+// be called before Run.
+func (m *Monitor) RegisterOptional(name string, c Checker) {
+	m.register(namedCheck{name: name, checker: c, optional: true})
+}
+
+func (m *Monitor) register(c namedCheck) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks = append(m.checks, c)
+	m.results[c.name] = Component{Status: StatusUnknown, Optional: c.optional}
+}
+
+// Run checks all dependencies at once and then every interval until ctx
+// is done.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.CheckAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll checks every dependency once and records the results.
+func (m *Monitor) CheckAll(ctx context.Context) {
+	m.mu.Lock()
+	checks := m.checks
+	m.mu.Unlock()
+
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, m.timeout)
+		start := m.now()
+		err := c.checker.Check(checkCtx)
+		cancel()
+
+		checkedAt := m.now()
+		result := Component{Status: StatusOK, CheckedAt: &checkedAt, LatencyMS: checkedAt.Sub(start).Milliseconds(), Optional: c.optional}
+		if err != nil {
+			result.Status, result.Error = StatusFailing, err.Error()
+		}
+		m.record(c.name, result)
+	}
+}
+
+func (m *Monitor) record(name string, result Component) {
+	m.mu.Lock()
+	prev := m.results[name].Status
+	m.results[name] = result
+	m.mu.Unlock()
+
+	switch {
+	case result.Status == StatusFailing && prev != StatusFailing:
+		m.logger.Warn("dependency check failing", "component", name, "error", result.Error)
+	case result.Status == StatusOK && prev == StatusFailing:
+		m.logger.Info("dependency check recovered", "component", name)
+	}
+}
+
+// Report returns the latest results.
+func (m *Monitor) Report() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := Report{Ready: true, Components: make(map[string]Component, len(m.results))}
+	for name, c := range m.results {
+		r.Components[name] = c
+		switch {
+		case c.Status == StatusOK:
+		case c.Optional:
+			r.Degraded = true
+		default:
+			r.Ready = false
+		}
+	}
+	return r
+}
@@ -0,0 +1,96 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/health"
+)
+
+func newMonitor() *health.Monitor {
+	return health.NewMonitor(time.Hour, time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestMonitor_NotReadyUntilChecked(t *testing.T) {
+	m := newMonitor()
+	m.Register("decks", health.CheckerFunc(func(context.Context) error { return nil }))
+
+	r := m.Report()
+	if r.Ready || r.Components["decks"].Status != health.StatusUnknown {
+		t.Fatalf("unchecked monitor reported %+v", r)
+	}
+
+	m.CheckAll(context.Background())
+	r = m.Report()
+	if !r.Ready || r.Components["decks"].Status != health.StatusOK || r.Components["decks"].CheckedAt == nil {
+		t.Errorf("after check: %+v", r)
+	}
+}
+
+func TestMonitor_FailingComponent(t *testing.T) {
+	var llmErr error = errors.New("upstream status 401")
+	m := newMonitor()
+	m.Register("decks", health.CheckerFunc(func(context.Context) error { return nil }))
+	m.Register("llm", health.CheckerFunc(func(context.Context) error { return llmErr }))
+
+	m.CheckAll(context.Background())
+	r := m.Report()
+	if r.Ready {
+		t.Error("ready with a failing component")
+	}
+	if c := r.Components["llm"]; c.Status != health.StatusFailing || c.Error != "upstream status 401" {
+		t.Errorf("llm = %+v", c)
+	}
+	if c := r.Components["decks"]; c.Status != health.StatusOK {
+		t.Errorf("decks = %+v", c)
+	}
+
+	llmErr = nil
+	m.CheckAll(context.Background())
+	if r := m.Report(); !r.Ready || r.Components["llm"].Error != "" {
+		t.Errorf("after recovery: %+v", r)
+	}
+}
+
+func TestMonitor_CheckTimeout(t *testing.T) {
+	m := health.NewMonitor(time.Hour, 10*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.Register("llm", health.CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	m.CheckAll(context.Background())
+	if c := m.Report().Components["llm"]; c.Status != health.StatusFailing {
+		t.Errorf("hung check reported %+v", c)
+	}
+}
+
+func TestMonitor_OptionalComponentDegrades(t *testing.T) {
+	var llmErr error = errors.New("upstream status 401")
+	m := newMonitor()
+	m.Register("decks", health.CheckerFunc(func(context.Context) error { return nil }))
+	m.RegisterOptional("llm", health.CheckerFunc(func(context.Context) error { return llmErr }))
+
+	if r := m.Report(); r.Ready || !r.Degraded {
+		t.Errorf("unchecked monitor reported %+v", r)
+	}
+
+	m.CheckAll(context.Background())
+	r := m.Report()
+	if !r.Ready || !r.Degraded {
+		t.Errorf("failing optional component: %+v", r)
+	}
+	if c := r.Components["llm"]; c.Status != health.StatusFailing || !c.Optional {
+		t.Errorf("llm = %+v", c)
+	}
+
+	llmErr = nil
+	m.CheckAll(context.Background())
+	if r := m.Report(); !r.Ready || r.Degraded {
+		t.Errorf("after recovery: %+v", r)
+	}
+}
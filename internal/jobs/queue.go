@@ -0,0 +1,301 @@
+// Package jobs runs work asynchronously on an in-process worker pool with
+// a bounded queue, keeps finished jobs for polling, and reports results to
+// webhooks.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	ErrQueueFull   = errors.New("job queue is full")
+	ErrQueueClosed = errors.New("job queue is shutting down")
+	ErrNotFound    = errors.New("job not found")
+)
+
+// Status is the lifecycle state of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Task is the work a job performs. The result must be JSON-encodable.
+// Errors are reported to clients through Failure; any other error is
+// reported as an internal error.
+type Task func(ctx context.Context) (any, error)
+
+// Failure is a task error that is safe to show to clients. Status is the
+// HTTP status the synchronous call would have returned and Code the stable
+// error code it would have carried.
+type Failure struct {
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Message   string `json:"detail,omitempty"`
+	Retryable bool   `json:"retryable"`
+}
+
+func (f *Failure) Error() string { return f.Message }
+
+// Job is a snapshot of a submitted task.
+type Job struct {
+	ID         string     `json:"id"`
+	Status     Status     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      *Failure   `json:"error,omitempty"`
+	Webhook    *Delivery  `json:"webhook,omitempty"`
+}
+
+// Delivery reports the state of a job's webhook.
+type Delivery struct {
+	URL       string `json:"url"`
+	Attempts  int    `json:"attempts"`
+	Delivered bool   `json:"delivered"`
+}
+
+// Queue runs tasks on a fixed number of workers. Finished jobs are kept
+// for ttl so they can be polled.
+type Queue struct {
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	finished []finishedJob // in the order jobs finished, so by expiry
+	pending  chan pending
+	closed   bool
+
+	ttl     time.Duration
+	webhook *Webhook
+	logger  *slog.Logger
+	now     func() time.Time
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type pending struct {
+	id   string
+	task Task
+}
+
+type finishedJob struct {
+	id string
+	at time.Time
+}
+
+// NewQueue starts workers that take tasks from a queue holding at most
+// depth jobs. webhook may be nil, which disables webhook delivery.
+func NewQueue(workers, depth int, ttl time.Duration, webhook *Webhook, logger *slog.Logger) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		jobs:    make(map[string]*Job),
+		pending: make(chan pending, depth),
+		ttl:     ttl,
+		webhook: webhook,
+		logger:  logger,
+		now:     time.Now,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for range workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// WebhooksEnabled reports whether jobs may ask for a webhook.
+func (q *Queue) WebhooksEnabled() bool {
+	return q.webhook != nil
+}
+
+// CheckWebhookURL reports why raw cannot be used as a job's webhook URL.
+// Webhooks must be enabled.
+func (q *Queue) CheckWebhookURL(raw string) error {
+	return q.webhook.CheckURL(raw)
+}
+
+// Submit enqueues task. If webhookURL is set the finished job is posted
+// there. It fails with ErrQueueFull when the queue is at capacity and
+// ErrQueueClosed once Shutdown has been called.
+func (q *Queue) Submit(task Task, webhookURL string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return Job{}, ErrQueueClosed
+	}
+	now := q.now()
+	q.prune(now)
+
+	job := &Job{ID: newID(), Status: StatusQueued, CreatedAt: now}
+	if webhookURL != "" && q.webhook != nil {
+		job.Webhook = &Delivery{URL: webhookURL}
+	}
+	select {
+	case q.pending <- pending{id: job.ID, task: task}:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[job.ID] = job
+	return snapshot(job), nil
+}
+
+// Get returns the current state of a job.
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return snapshot(job), nil
+}
+
+// Shutdown stops accepting jobs and waits for queued and running jobs,
+// including their webhooks, to finish. If ctx ends first, work still in
+// progress is cancelled and ctx's error is returned.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.pending)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for p := range q.pending {
+		q.run(p)
+	}
+}
+
+func (q *Queue) run(p pending) {
+	q.update(p.id, func(j *Job) {
+		now := q.now()
+		j.Status = StatusRunning
+		j.StartedAt = &now
+	})
+
+	result, err := p.task(q.ctx)
+
+	job := q.update(p.id, func(j *Job) {
+		now := q.now()
+		j.FinishedAt = &now
+		q.finished = append(q.finished, finishedJob{id: j.ID, at: now})
+		if err != nil {
+			j.Status = StatusFailed
+			j.Error = failure(err)
+			return
+		}
+		j.Status = StatusSucceeded
+		j.Result = result
+	})
+	if err != nil {
+		q.logger.Warn("job failed", "job_id", p.id, "error", err)
+	}
+
+	if job.Webhook != nil {
+		q.notify(job)
+	}
+}
+
+// notify posts the finished job to its webhook and records the outcome.
+func (q *Queue) notify(job Job) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		q.logger.Error("encode webhook payload", "job_id", job.ID, "error", err)
+		return
+	}
+	attempts, err := q.webhook.Deliver(q.ctx, job.Webhook.URL, job.ID, body)
+	if err != nil {
+		q.logger.Warn("webhook delivery failed", "job_id", job.ID, "attempts", attempts, "error", err)
+	}
+	q.update(job.ID, func(j *Job) {
+		j.Webhook.Attempts = attempts
+		j.Webhook.Delivered = err == nil
+	})
+}
+
+// update applies fn to the stored job and returns a snapshot.
+func (q *Queue) update(id string, fn func(*Job)) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}
+	}
+	fn(job)
+	return snapshot(job)
+}
+
+// prune drops jobs that finished more than ttl ago. They are at the front
+// of q.finished, so each call only pays for the jobs it removes.
+func (q *Queue) prune(now time.Time) {
+	if q.ttl <= 0 {
+		q.finished = nil
+		return
+	}
+	n := 0
+	for ; n < len(q.finished) && now.Sub(q.finished[n].at) >= q.ttl; n++ {
+		delete(q.jobs, q.finished[n].id)
+		q.finished[n] = finishedJob{}
+	}
+	q.finished = q.finished[n:]
+}
+
+func failure(err error) *Failure {
+	var f *Failure
+	if errors.As(err, &f) {
+		out := *f
+		return &out
+	}
+	return &Failure{Status: 500, Code: "internal_error", Message: "internal error"}
+}
+
+// snapshot copies j so callers never share the stored webhook state.
+func snapshot(j *Job) Job {
+	out := *j
+	if j.Webhook != nil {
+		w := *j.Webhook
+		out.Webhook = &w
+	}
+	return out
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Webhook request headers. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderJobID     = "X-Webhook-Id"
+)
+
+// ErrBlockedAddress is returned for webhook addresses that are not public,
+// such as loopback, private networks and cloud metadata endpoints.
+var ErrBlockedAddress = errors.New("webhook address is not public")
+
+// Webhook posts signed JSON payloads, retrying with exponential backoff on
+// network errors, 429 and 5xx responses.
+type Webhook struct {
+	client       *http.Client
+	secret       []byte
+	maxAttempts  int
+	backoff      time.Duration
+	allowedHosts []string
+}
+
+// NewWebhook returns a Webhook posting with client, which should come from
+// NewClient. If allowedHosts is set, webhook URLs must name one of them;
+// "*.example.com" allows any subdomain of example.com.
+func NewWebhook(client *http.Client, secret string, maxAttempts int, backoff time.Duration, allowedHosts []string) *Webhook {
+	return &Webhook{
+		client:       client,
+		secret:       []byte(secret),
+		maxAttempts:  max(maxAttempts, 1),
+		backoff:      backoff,
+		allowedHosts: allowedHosts,
+	}
+}
+
+// CheckURL reports why raw cannot be a webhook URL. It must be an absolute
+// https URL, name an allowed host and, if the host is an IP address, a
+// public one. Host names are checked again when dialing, by the client
+// from NewClient, which also covers DNS rebinding.
+func (w *Webhook) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return fmt.Errorf("webhook_url must be an absolute https URL")
+	}
+	host := strings.ToLower(u.Hostname())
+	if len(w.allowedHosts) > 0 && !slices.ContainsFunc(w.allowedHosts, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			return strings.HasSuffix(host, suffix)
+		}
+		return host == allowed
+	}) {
+		return fmt.Errorf("webhook_url host %q is not allowed", host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for webhooks. It refuses to connect to
+// addresses that are not public, whatever name resolved to them, ignores
+// proxy settings so that check cannot be bypassed, and does not follow
+// redirects: a redirect counts as a failed delivery.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublic}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialPublic runs after name resolution, on the address about to be dialed.
+func dialPublic(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	return nil
+}
+
+// nonPublic lists ranges that are global unicast by netip's definition but
+// still reach internal networks.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, may embed a private IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+}
+
+// publicIP rejects loopback, private, link-local (which holds the cloud
+// metadata endpoint 169.254.169.254), unspecified and multicast addresses.
+func publicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	return !slices.ContainsFunc(nonPublic, func(p netip.Prefix) bool { return p.Contains(ip) })
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts body to url and returns the number of attempts made.
+func (w *Webhook) Deliver(ctx context.Context, url, jobID string, body []byte) (int, error) {
+	var lastErr error
+	delay := w.backoff
+	for attempt := 1; attempt <= w.maxAttempts; attempt++ {
+		retry, err := w.post(ctx, url, jobID, body)
+		if err == nil {
+			return attempt, nil
+		}
+		lastErr = err
+		if !retry || attempt == w.maxAttempts {
+			return attempt, lastErr
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("%w (last error: %w)", ctx.Err(), lastErr)
+		}
+		delay *= 2
+	}
+	return w.maxAttempts, lastErr
+}
+
+// post makes one attempt and reports whether a failure is worth retrying.
+func (w *Webhook) post(ctx context.Context, url, jobID string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("build webhook request: %w", err)
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, Sign(w.secret, ts, body))
+	req.Header.Set(HeaderJobID, jobID)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook call: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook status %d", resp.StatusCode)
+}
@@ -0,0 +1,389 @@
+// Package jsonschema validates decoded JSON values against the subset of
+// JSON Schema used by this service: the LLM output contract and the
+// OpenAPI document. Schemas and instances are plain decoded JSON
+// (map[string]any, []any, string, float64, bool, nil).
+//
+// Supported keywords: $ref (local "#/..." pointers), type, enum, const,
+// required, properties, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, minimum, maximum, pattern, allOf, anyOf, oneOf.
+// Unknown keywords such as format, description and example are ignored.
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator checks instances against a root schema and resolves local
+// $ref pointers within it.
+type Validator struct {
+	root map[string]any
+}
+
+func New(root map[string]any) *Validator {
+	return &Validator{root: root}
+}
+
+// Root returns the root schema.
+func (v *Validator) Root() map[string]any {
+	return v.root
+}
+
+// Validate checks instance against the root schema.
+func (v *Validator) Validate(instance any) []string {
+	return v.ValidateSchema(v.root, instance)
+}
+
+// ValidateSchema checks instance against schema, which may be any
+// sub-schema of the root. Each violation is "path: problem" with path in
+// "$.field[0]" form.
+func (v *Validator) ValidateSchema(schema map[string]any, instance any) []string {
+	violations := v.Check(schema, instance)
+	out := make([]string, len(violations))
+	for i, vi := range violations {
+		out[i] = vi.String()
+	}
+	return out
+}
+
+// Violation is one way in which an instance does not match a schema.
+type Violation struct {
+	Path    string         // instance location in "$.field[0]" form
+	Keyword string         // schema keyword that failed, e.g. "maxLength"
+	Message string         // e.g. "must be at most 3 characters"
+	Schema  map[string]any // schema that holds Keyword
+	// Property is the missing or unexpected property for the required and
+	// additionalProperties keywords; Path is then the enclosing object.
+	Property string
+}
+
+func (vi Violation) String() string {
+	return vi.Path + ": " + vi.Message
+}
+
+// Check is ValidateSchema with structured violations.
+func (v *Validator) Check(schema map[string]any, instance any) []Violation {
+	var out []Violation
+	v.validate(schema, instance, "$", &out, 0)
+	return out
+}
+
+// maxDepth guards against cyclic $refs.
+const maxDepth = 64
+
+func fail(out *[]Violation, schema map[string]any, path, keyword, format string, args ...any) {
+	*out = append(*out, Violation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...), Schema: schema})
+}
+
+func (v *Validator) validate(schema map[string]any, inst any, path string, out *[]Violation, depth int) {
+	if depth > maxDepth {
+		fail(out, schema, path, "$ref", "schema nesting too deep")
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			fail(out, schema, path, "$ref", "%v", err)
+			return
+		}
+		v.validate(target, inst, path, out, depth+1)
+		return
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, inst) {
+		fail(out, schema, path, "type", "must be of type %s, got %s", typeNames(t), typeOf(inst))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !contains(enum, inst) {
+		fail(out, schema, path, "enum", "must be one of %s", formatValues(enum))
+	}
+	if c, ok := schema["const"]; ok && !equal(c, inst) {
+		fail(out, schema, path, "const", "must equal %s", formatValues([]any{c}))
+	}
+
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		subs, ok := schema[key].([]any)
+		if !ok {
+			continue
+		}
+		v.combine(schema, key, subs, inst, path, out, depth)
+	}
+
+	switch x := inst.(type) {
+	case map[string]any:
+		v.validateObject(schema, x, path, out, depth)
+	case []any:
+		v.validateArray(schema, x, path, out, depth)
+	case string:
+		validateString(schema, x, path, out)
+	case float64:
+		validateNumber(schema, x, path, out)
+	}
+}
+
+func (v *Validator) combine(schema map[string]any, key string, subs []any, inst any, path string, out *[]Violation, depth int) {
+	matched := 0
+	var first []string
+	for _, s := range subs {
+		sub, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		var errs []Violation
+		v.validate(sub, inst, path, &errs, depth+1)
+		if key == "allOf" {
+			*out = append(*out, errs...)
+			continue
+		}
+		if len(errs) == 0 {
+			matched++
+		} else if first == nil {
+			for _, e := range errs {
+				first = append(first, e.String())
+			}
+		}
+	}
+	switch {
+	case key == "anyOf" && matched == 0:
+		fail(out, schema, path, key, "must match at least one allowed schema (%s)", strings.Join(first, "; "))
+	case key == "oneOf" && matched != 1:
+		fail(out, schema, path, key, "must match exactly one allowed schema, matched %d", matched)
+	}
+}
+
+func (v *Validator) validateObject(schema, obj map[string]any, path string, out *[]Violation, depth int) {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := obj[name]; !present {
+				*out = append(*out, Violation{Path: path, Keyword: "required", Message: fmt.Sprintf("missing required property %q", name), Schema: schema, Property: name})
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	for _, name := range sortedKeys(obj) {
+		val := obj[name]
+		child := path + "." + name
+		if ps, ok := props[name].(map[string]any); ok {
+			v.validate(ps, val, child, out, depth+1)
+			continue
+		}
+		switch ap := schema["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				*out = append(*out, Violation{Path: path, Keyword: "additionalProperties", Message: fmt.Sprintf("unexpected property %q", name), Schema: schema, Property: name})
+			}
+		case map[string]any:
+			v.validate(ap, val, child, out, depth+1)
+		}
+	}
+}
+
+func (v *Validator) validateArray(schema map[string]any, arr []any, path string, out *[]Violation, depth int) {
+	if n, ok := number(schema["minItems"]); ok && float64(len(arr)) < n {
+		fail(out, schema, path, "minItems", "must contain at least %d items, got %d", int(n), len(arr))
+	}
+	if n, ok := number(schema["maxItems"]); ok && float64(len(arr)) > n {
+		fail(out, schema, path, "maxItems", "must contain at most %d items, got %d", int(n), len(arr))
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range arr {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i), out, depth+1)
+		}
+	}
+}
+
+func validateString(schema map[string]any, s, path string, out *[]Violation) {
+	length := float64(utf8.RuneCountInString(s))
+	if n, ok := number(schema["minLength"]); ok && length < n {
+		if n == 1 {
+			fail(out, schema, path, "minLength", "must not be empty")
+		} else {
+			fail(out, schema, path, "minLength", "must be at least %d characters", int(n))
+		}
+	}
+	if n, ok := number(schema["maxLength"]); ok && length > n {
+		fail(out, schema, path, "maxLength", "must be at most %d characters", int(n))
+	}
+	if p, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			fail(out, schema, path, "pattern", "invalid pattern %q in schema", p)
+		} else if !re.MatchString(s) {
+			fail(out, schema, path, "pattern", "must match pattern %q", p)
+		}
+	}
+}
+
+func validateNumber(schema map[string]any, f float64, path string, out *[]Violation) {
+	if n, ok := number(schema["minimum"]); ok && f < n {
+		fail(out, schema, path, "minimum", "must be >= %v", n)
+	}
+	if n, ok := number(schema["maximum"]); ok && f > n {
+		fail(out, schema, path, "maximum", "must be <= %v", n)
+	}
+}
+
+// resolve follows a local JSON pointer such as "#/components/schemas/Card".
+func (v *Validator) resolve(ref string) (map[string]any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are allowed", ref)
+	}
+	var node any = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	target, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("$ref %q does not point to a schema", ref)
+	}
+	return target, nil
+}
+
+func matchesType(t, inst any) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, inst)
+	case []any:
+		for _, x := range tt {
+			if s, ok := x.(string); ok && isType(s, inst) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func isType(name string, inst any) bool {
+	switch name {
+	case "object":
+		_, ok := inst.(map[string]any)
+		return ok
+	case "array":
+		_, ok := inst.([]any)
+		return ok
+	case "string":
+		_, ok := inst.(string)
+		return ok
+	case "boolean":
+		_, ok := inst.(bool)
+		return ok
+	case "null":
+		return inst == nil
+	case "number":
+		_, ok := number(inst)
+		return ok
+	case "integer":
+		f, ok := number(inst)
+		return ok && f == math.Trunc(f)
+	default:
+		return false
+	}
+}
+
+func typeOf(inst any) string {
+	switch inst.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		if _, ok := number(inst); ok {
+			return "number"
+		}
+		return fmt.Sprintf("%T", inst)
+	}
+}
+
+func typeNames(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, len(list))
+		for i, x := range list {
+			names[i] = fmt.Sprint(x)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// number accepts the numeric types produced by encoding/json and YAML
+// decoders alike.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func contains(list []any, v any) bool {
+	for _, x := range list {
+		if equal(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	if fa, ok := number(a); ok {
+		fb, ok := number(b)
+		return ok && fa == fb
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b) && typeOf(a) == typeOf(b)
+}
+
+func formatValues(vals []any) string {
+	parts := make([]string, len(vals))
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			parts[i] = fmt.Sprintf("%q", s)
+		} else {
+			parts[i] = fmt.Sprint(v)
+		}
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,105 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/jsonschema"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+const rootSchema = `{
+  "$ref": "#/definitions/Reading",
+  "definitions": {
+    "Reading": {
+      "type": "object",
+      "required": ["n", "tags"],
+      "additionalProperties": false,
+      "properties": {
+        "n": {"type": "integer", "minimum": 1, "maximum": 10},
+        "name": {"type": ["string", "null"], "maxLength": 3, "pattern": "^[a-z]+$"},
+        "tags": {"type": "array", "minItems": 1, "items": {"$ref": "#/definitions/Tag"}},
+        "mode": {"oneOf": [{"const": "a"}, {"const": "b"}]}
+      }
+    },
+    "Tag": {"type": "string", "enum": ["x", "y"]}
+  }
+}`
+
+func TestValidate_Valid(t *testing.T) {
+	v := jsonschema.New(decode(t, rootSchema).(map[string]any))
+	if errs := v.Validate(decode(t, `{"n": 3, "name": null, "tags": ["x"], "mode": "b"}`)); len(errs) != 0 {
+		t.Errorf("unexpected violations: %v", errs)
+	}
+}
+
+func TestValidate_Violations(t *testing.T) {
+	v := jsonschema.New(decode(t, rootSchema).(map[string]any))
+	errs := v.Validate(decode(t, `{"n": 2.5, "name": "ABCD", "tags": ["z"], "mode": "c", "extra": true}`))
+
+	got := strings.Join(errs, "\n")
+	for _, want := range []string{
+		`$: unexpected property "extra"`,
+		`$.n: must be of type integer, got number`,
+		`$.name: must be at most 3 characters`,
+		`$.name: must match pattern "^[a-z]+$"`,
+		`$.tags[0]: must be one of ["x", "y"]`,
+		`$.mode: must match exactly one allowed schema, matched 0`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing violation %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestValidate_MissingRequired(t *testing.T) {
+	v := jsonschema.New(decode(t, rootSchema).(map[string]any))
+	errs := v.Validate(decode(t, `{"n": 0}`))
+	got := strings.Join(errs, "\n")
+	for _, want := range []string{`$: missing required property "tags"`, `$.n: must be >= 1`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing violation %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestValidate_BadRef(t *testing.T) {
+	v := jsonschema.New(map[string]any{"$ref": "#/nope"})
+	if errs := v.Validate("x"); len(errs) != 1 || !strings.Contains(errs[0], "unresolvable") {
+		t.Errorf("expected unresolvable ref, got %v", errs)
+	}
+}
+
+func TestCheck_Structured(t *testing.T) {
+	v := jsonschema.New(decode(t, rootSchema).(map[string]any))
+	got := v.Check(v.Root(), decode(t, `{"n": 11}`))
+
+	want := map[string]jsonschema.Violation{
+		"maximum":  {Path: "$.n", Keyword: "maximum"},
+		"required": {Path: "$", Keyword: "required", Property: "tags"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d violations, want %d: %v", len(got), len(want), got)
+	}
+	for _, vi := range got {
+		w, ok := want[vi.Keyword]
+		if !ok || vi.Path != w.Path || vi.Property != w.Property {
+			t.Errorf("unexpected violation %+v", vi)
+		}
+		if vi.Schema == nil {
+			t.Errorf("%s: schema not reported", vi.Keyword)
+		}
+	}
+	if got[0].Keyword == "maximum" && got[0].Schema["maximum"] == nil {
+		t.Error("maximum violation should point at the schema holding maximum")
+	}
+}
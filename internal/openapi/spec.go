@@ -0,0 +1,249 @@
+// Package openapi validates HTTP requests and responses against the
+// service's OpenAPI document, so the limits it documents are the limits
+// enforced.
+//
+// Schemas are checked with internal/jsonschema. Two extensions map
+// violations to stable error codes: "x-error-code" on a schema applies to
+// every violation of it, and "x-error-codes" maps individual keywords
+// (e.g. "maxLength", "required") to codes. An operation can set
+// "x-validate-request-body: false" when its handler validates the body
+// itself.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/randomtoy/taas-go/internal/jsonschema"
+)
+
+// Spec is a loaded OpenAPI document.
+type Spec struct {
+	validator  *jsonschema.Validator
+	operations []*Operation
+}
+
+// Operation is one method on one path of the document.
+type Operation struct {
+	ID     string
+	Method string
+	Path   string // template such as /v1/readings/{id}
+
+	spec         *Spec
+	segments     []string
+	params       []param
+	body         map[string]any // JSON request body schema; nil if none
+	bodyRequired bool
+	validateBody bool
+	responses    map[string]map[string]map[string]any // status -> media type -> schema
+}
+
+type param struct {
+	name     string
+	in       string
+	required bool
+	schema   map[string]any
+}
+
+var methods = []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch, http.MethodHead, http.MethodOptions}
+
+// Load parses an OpenAPI 3 document in YAML or JSON.
+func Load(doc []byte) (*Spec, error) {
+	var root map[string]any
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+	s := &Spec{validator: jsonschema.New(root)}
+
+	paths, _ := root["paths"].(map[string]any)
+	for path, item := range paths {
+		pathItem, _ := item.(map[string]any)
+		shared, err := s.params(pathItem["parameters"])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, method := range methods {
+			raw, ok := pathItem[strings.ToLower(method)].(map[string]any)
+			if !ok {
+				continue
+			}
+			op, err := s.operation(method, path, raw, shared)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			s.operations = append(s.operations, op)
+		}
+	}
+	sort.Slice(s.operations, func(i, j int) bool {
+		a, b := s.operations[i], s.operations[j]
+		return a.Path < b.Path || a.Path == b.Path && a.Method < b.Method
+	})
+	return s, nil
+}
+
+func (s *Spec) operation(method, path string, raw map[string]any, shared []param) (*Operation, error) {
+	own, err := s.params(raw["parameters"])
+	if err != nil {
+		return nil, err
+	}
+	id, _ := raw["operationId"].(string)
+	op := &Operation{
+		ID:           id,
+		Method:       method,
+		Path:         path,
+		spec:         s,
+		segments:     strings.Split(strings.Trim(path, "/"), "/"),
+		params:       slices.Concat(shared, own),
+		validateBody: raw["x-validate-request-body"] != false,
+		responses:    make(map[string]map[string]map[string]any),
+	}
+
+	if rb, ok := raw["requestBody"].(map[string]any); ok {
+		if rb, err = s.deref(rb); err != nil {
+			return nil, err
+		}
+		op.bodyRequired, _ = rb["required"].(bool)
+		op.body = mediaSchema(rb, "application/json")
+	}
+
+	responses, _ := raw["responses"].(map[string]any)
+	for status, r := range responses {
+		resp, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		if resp, err = s.deref(resp); err != nil {
+			return nil, err
+		}
+		media := make(map[string]map[string]any)
+		content, _ := resp["content"].(map[string]any)
+		for mt := range content {
+			media[mt] = mediaSchema(resp, mt)
+		}
+		op.responses[status] = media
+	}
+	return op, nil
+}
+
+func (s *Spec) params(raw any) ([]param, error) {
+	list, _ := raw.([]any)
+	out := make([]param, 0, len(list))
+	for _, item := range list {
+		p, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		p, err := s.deref(p)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := p["name"].(string)
+		in, _ := p["in"].(string)
+		required, _ := p["required"].(bool)
+		schema, _ := p["schema"].(map[string]any)
+		if in == "header" {
+			name = http.CanonicalHeaderKey(name)
+		}
+		out = append(out, param{name: name, in: in, required: required, schema: schema})
+	}
+	return out, nil
+}
+
+// deref follows a $ref to a component such as a parameter or response.
+func (s *Spec) deref(node map[string]any) (map[string]any, error) {
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node, nil
+	}
+	return s.lookup(ref)
+}
+
+func (s *Spec) lookup(ref string) (map[string]any, error) {
+	var node any = s.validator.Root()
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	target, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("$ref %q does not point to an object", ref)
+	}
+	return target, nil
+}
+
+func mediaSchema(node map[string]any, mediaType string) map[string]any {
+	content, _ := node["content"].(map[string]any)
+	media, _ := content[mediaType].(map[string]any)
+	schema, _ := media["schema"].(map[string]any)
+	return schema
+}
+
+// Operations lists every documented operation, ordered by path and method.
+func (s *Spec) Operations() []*Operation {
+	return s.operations
+}
+
+// Schema returns the component schema with the given name, or nil.
+func (s *Spec) Schema(name string) map[string]any {
+	schema, err := s.lookup("#/components/schemas/" + name)
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
+// Find returns the operation serving method and path, with the values of
+// its path parameters, or nil if the document does not describe it. Static
+// segments win over parameters, so /v1/readings/batch is not read as
+// /v1/readings/{id}.
+func (s *Spec) Find(method, path string) (*Operation, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var best *Operation
+	bestStatic := -1
+	for _, op := range s.operations {
+		if op.Method != method || len(op.segments) != len(segments) {
+			continue
+		}
+		static, ok := 0, true
+		for i, seg := range op.segments {
+			switch {
+			case isParam(seg):
+			case seg == segments[i]:
+				static++
+			default:
+				ok = false
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok && static > bestStatic {
+			best, bestStatic = op, static
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	values := make(map[string]string)
+	for i, seg := range best.segments {
+		if isParam(seg) {
+			values[strings.Trim(seg, "{}")] = segments[i]
+		}
+	}
+	return best, values
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -0,0 +1,137 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/randomtoy/taas-go/api"
+	"github.com/randomtoy/taas-go/internal/openapi"
+)
+
+func load(t *testing.T) *openapi.Spec {
+	t.Helper()
+	spec, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+func TestFind(t *testing.T) {
+	spec := load(t)
+	tests := []struct {
+		method, path, id string
+		params           map[string]string
+	}{
+		{http.MethodGet, "/v1/tarot", "readTarot", nil},
+		{http.MethodPost, "/v1/readings/batch", "createReadingBatch", nil},
+		{http.MethodGet, "/v1/readings/abc", "getReading", map[string]string{"id": "abc"}},
+		{http.MethodPost, "/v1/readings/abc/followups", "createFollowUp", map[string]string{"id": "abc"}},
+		{http.MethodDelete, "/v1/tarot", "", nil},
+		{http.MethodGet, "/v1/unknown", "", nil},
+	}
+	for _, tt := range tests {
+		op, params := spec.Find(tt.method, tt.path)
+		switch {
+		case tt.id == "" && op != nil:
+			t.Errorf("%s %s: unexpected match %s", tt.method, tt.path, op.ID)
+		case tt.id != "" && (op == nil || op.ID != tt.id):
+			t.Errorf("%s %s: got %v, want %s", tt.method, tt.path, op, tt.id)
+		case tt.id != "" && params["id"] != tt.params["id"]:
+			t.Errorf("%s %s: params = %v", tt.method, tt.path, params)
+		}
+	}
+}
+
+func validate(t *testing.T, spec *openapi.Spec, r *http.Request) []openapi.FieldError {
+	t.Helper()
+	op, params := spec.Find(r.Method, r.URL.Path)
+	if op == nil {
+		t.Fatalf("no operation for %s %s", r.Method, r.URL.Path)
+	}
+	errs, err := op.ValidateRequest(r, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return errs
+}
+
+func TestValidateRequest_Query(t *testing.T) {
+	spec := load(t)
+
+	if errs := validate(t, spec, httptest.NewRequest(http.MethodGet, "/v1/tarot?n=5&style=poetic", nil)); len(errs) != 0 {
+		t.Errorf("valid request rejected: %+v", errs)
+	}
+
+	errs := validate(t, spec, httptest.NewRequest(http.MethodGet, "/v1/tarot?n=11&style=grim&q="+strings.Repeat("a", 501), nil))
+	codes := map[string]string{}
+	for _, e := range errs {
+		codes[e.Field] = e.Code
+	}
+	want := map[string]string{"n": "invalid_n", "style": "unknown_style", "q": "question_too_long"}
+	for field, code := range want {
+		if codes[field] != code {
+			t.Errorf("%s: code %q, want %q (all: %+v)", field, codes[field], code, errs)
+		}
+	}
+
+	errs = validate(t, spec, httptest.NewRequest(http.MethodGet, "/v1/tarot?n=three", nil))
+	if len(errs) != 1 || errs[0].Code != "invalid_n" {
+		t.Errorf("non-integer n: %+v", errs)
+	}
+}
+
+func TestValidateRequest_Body(t *testing.T) {
+	spec := load(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/readings/abc/followups", strings.NewReader(`{}`))
+	errs := validate(t, spec, r)
+	if len(errs) != 1 || errs[0].Field != "question" || errs[0].Code != "question_required" {
+		t.Errorf("missing question: %+v", errs)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/readings/abc/clarifiers", strings.NewReader(`{"position": 1, "count": 4}`))
+	errs = validate(t, spec, r)
+	if len(errs) != 1 || errs[0].Field != "count" || errs[0].Code != "invalid_clarifier_count" {
+		t.Errorf("bad count: %+v", errs)
+	}
+	body := make([]byte, 64)
+	if n, _ := r.Body.Read(body); !strings.Contains(string(body[:n]), "count") {
+		t.Error("body was not restored for the handler")
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/readings", strings.NewReader(`{"n":`))
+	op, params := spec.Find(r.Method, r.URL.Path)
+	if _, err := op.ValidateRequest(r, params); err != openapi.ErrMalformedBody {
+		t.Errorf("malformed body: got %v", err)
+	}
+}
+
+func TestValidateRequest_BatchBodyLeftToHandler(t *testing.T) {
+	spec := load(t)
+	r := httptest.NewRequest(http.MethodPost, "/v1/readings/batch", strings.NewReader(`{"items": [{"n": 99}]}`))
+	if errs := validate(t, spec, r); len(errs) != 0 {
+		t.Errorf("batch items must be validated by the handler, got %+v", errs)
+	}
+	if errs := spec.CheckSchema(spec.Schema("BatchItem"), map[string]any{"n": 99.0}); len(errs) != 1 || errs[0].Code != "invalid_n" {
+		t.Errorf("BatchItem schema: %+v", errs)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec := load(t)
+	op, _ := spec.Find(http.MethodGet, "/v1/styles")
+
+	ok := []byte(`[{"style":"neutral","description":"Balanced.","default":true}]`)
+	if problems := op.ValidateResponse(http.StatusOK, "application/json; charset=UTF-8", ok); len(problems) != 0 {
+		t.Errorf("valid response rejected: %v", problems)
+	}
+	if problems := op.ValidateResponse(http.StatusOK, "application/json", []byte(`[{"style":1}]`)); len(problems) == 0 {
+		t.Error("invalid response accepted")
+	}
+	if problems := op.ValidateResponse(http.StatusTeapot, "application/json", ok); len(problems) != 1 {
+		t.Errorf("undocumented status: %v", problems)
+	}
+}
@@ -0,0 +1,192 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrMalformedBody is returned when a JSON request body cannot be decoded.
+var ErrMalformedBody = errors.New("request body is not valid JSON")
+
+// FieldError is one invalid part of a request. Code is the schema's
+// x-error-code for the violation, or empty if it has none.
+type FieldError struct {
+	In     string // "query", "header", "path" or "body"
+	Field  string // parameter name or body path such as "items[0].n"
+	Code   string
+	Detail string
+}
+
+// ValidateRequest checks the parameters and JSON body of r, whose path
+// parameters are pathParams. The body is read and replaced so handlers can
+// still bind it.
+func (op *Operation) ValidateRequest(r *http.Request, pathParams map[string]string) ([]FieldError, error) {
+	var errs []FieldError
+	for _, p := range op.params {
+		var raw string
+		var present bool
+		switch p.in {
+		case "query":
+			raw, present = r.URL.Query().Get(p.name), r.URL.Query().Has(p.name)
+		case "header":
+			values := r.Header.Values(p.name)
+			raw, present = strings.Join(values, ","), len(values) > 0
+		case "path":
+			raw, present = pathParams[p.name]
+		default:
+			continue
+		}
+		if !present {
+			if p.required {
+				errs = append(errs, FieldError{In: p.in, Field: p.name, Code: errorCode(p.schema, "required"), Detail: p.name + " is required"})
+			}
+			continue
+		}
+		errs = append(errs, op.checkParam(p, raw)...)
+	}
+
+	if op.body == nil || !op.validateBody {
+		return errs, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.bodyRequired {
+			errs = append(errs, FieldError{In: "body", Detail: "request body is required"})
+		}
+		return errs, nil
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, ErrMalformedBody
+	}
+	return append(errs, op.spec.CheckSchema(op.body, doc)...), nil
+}
+
+// checkParam converts a raw parameter to the type its schema declares and
+// validates it.
+func (op *Operation) checkParam(p param, raw string) []FieldError {
+	var value any = raw
+	var convErr error
+	switch p.schema["type"] {
+	case "integer":
+		var n int64
+		n, convErr = strconv.ParseInt(raw, 10, 64)
+		value = float64(n)
+	case "number":
+		value, convErr = strconv.ParseFloat(raw, 64)
+	case "boolean":
+		value, convErr = strconv.ParseBool(raw)
+	}
+	if convErr != nil {
+		return []FieldError{{In: p.in, Field: p.name, Code: errorCode(p.schema, "type"), Detail: fmt.Sprintf("%s must be of type %s", p.name, p.schema["type"])}}
+	}
+
+	violations := op.spec.validator.Check(p.schema, value)
+	out := make([]FieldError, len(violations))
+	for i, v := range violations {
+		out[i] = FieldError{In: p.in, Field: p.name, Code: errorCode(v.Schema, v.Keyword), Detail: p.name + " " + v.Message}
+	}
+	return out
+}
+
+// CheckSchema validates a decoded JSON value against schema, reporting
+// violations as body field errors.
+func (s *Spec) CheckSchema(schema map[string]any, doc any) []FieldError {
+	violations := s.validator.Check(schema, doc)
+	out := make([]FieldError, len(violations))
+	for i, v := range violations {
+		field := strings.TrimPrefix(strings.TrimPrefix(v.Path, "$"), ".")
+		code := errorCode(v.Schema, v.Keyword)
+		if v.Property != "" {
+			field = strings.TrimPrefix(field+"."+v.Property, ".")
+			props, _ := v.Schema["properties"].(map[string]any)
+			if prop, ok := props[v.Property].(map[string]any); ok {
+				code = errorCode(prop, v.Keyword)
+			}
+		}
+		var detail string
+		switch {
+		case v.Keyword == "required":
+			detail = field + " is required"
+		case field == "":
+			detail = "request body " + v.Message
+		default:
+			detail = field + " " + v.Message
+		}
+		out[i] = FieldError{In: "body", Field: field, Code: code, Detail: detail}
+	}
+	return out
+}
+
+// errorCode looks up the code for a keyword violation of schema.
+func errorCode(schema map[string]any, keyword string) string {
+	if codes, ok := schema["x-error-codes"].(map[string]any); ok {
+		if code, ok := codes[keyword].(string); ok {
+			return code
+		}
+	}
+	code, _ := schema["x-error-code"].(string)
+	return code
+}
+
+// ValidateResponse checks a response against the documented responses of
+// the operation and returns a description of each mismatch. NDJSON bodies
+// are checked line by line.
+func (op *Operation) ValidateResponse(status int, contentType string, body []byte) []string {
+	media, ok := op.responses[strconv.Itoa(status)]
+	if !ok {
+		if media, ok = op.responses["default"]; !ok {
+			return []string{fmt.Sprintf("status %d is not documented", status)}
+		}
+	}
+	if len(media) == 0 || len(body) == 0 {
+		return nil
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid Content-Type %q", contentType)}
+	}
+	schema, ok := media[mt]
+	if !ok {
+		return []string{fmt.Sprintf("Content-Type %s is not documented for status %d", mt, status)}
+	}
+	if schema == nil || !strings.Contains(mt, "json") {
+		return nil
+	}
+
+	if mt != "application/x-ndjson" {
+		return op.checkJSON(schema, body, "")
+	}
+	var out []string
+	lines := bufio.NewScanner(bytes.NewReader(body))
+	lines.Buffer(nil, len(body)+1)
+	for n := 1; lines.Scan(); n++ {
+		out = append(out, op.checkJSON(schema, lines.Bytes(), fmt.Sprintf("line %d: ", n))...)
+	}
+	return out
+}
+
+func (op *Operation) checkJSON(schema map[string]any, body []byte, prefix string) []string {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return []string{prefix + "body is not valid JSON"}
+	}
+	var out []string
+	for _, v := range op.spec.validator.Check(schema, doc) {
+		out = append(out, prefix+v.String())
+	}
+	return out
+}
@@ -1,14 +1,45 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 // InterpretInput holds everything the LLM needs to generate an interpretation.
 type InterpretInput struct {
-	DeckID   string
-	Spread   string
-	Question string
-	Cards    []CardInput
-	Lang     string // BCP 47 language code, e.g. "en", "ru", "es"
+	DeckID    string
+	Spread    string
+	Question  string
+	Cards     []CardInput
+	Lang      string   // BCP 47 language code, e.g. "en", "ru", "es"
+	Style     string   // persona from domain.Personas; empty means domain.DefaultStyle
+	Length    string   // domain.Length: short, medium or long; empty leaves the defaults
+	Guidance  []string // extra care instructions for the prompt, e.g. from safety guardrails
+	ClientKey string   // caller identity for spend accounting; never sent to the LLM
+	Seed      *int64   // reading seed, if the caller gave one; providers that support it get a seed derived from it
+
+	// ClarifiesPosition is set when Cards are clarifiers drawn for that
+	// position of an earlier spread; Context then holds the spread itself.
+	ClarifiesPosition int
+	Context           []CardInput
+
+	// History holds earlier turns of a conversation about the same cards,
+	// oldest first, starting with the original question. When it is set,
+	// Question is a follow-up.
+	History []Message
+}
+
+// Message roles used in InterpretInput.History.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one earlier turn in a conversation. User messages hold the
+// question as asked; assistant messages hold the interpretation JSON.
+type Message struct {
+	Role    string
+	Content string
 }
 
 // CardInput is a simplified card representation for the LLM prompt.
@@ -20,12 +51,97 @@ type CardInput struct {
 	Short       string
 }
 
+// Usage reports the tokens consumed to produce an interpretation.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// ModelUsage is the tokens one model used.
+type ModelUsage struct {
+	Model string
+	Usage Usage
+}
+
+// SpendError wraps an interpretation error with the tokens upstream calls
+// used before it failed, so they can still be accounted for.
+type SpendError struct {
+	Spend []ModelUsage
+	Err   error
+}
+
+func (e *SpendError) Error() string { return e.Err.Error() }
+
+func (e *SpendError) Unwrap() error { return e.Err }
+
+// SpendOf returns the tokens spent by a failed interpretation, if err
+// carries them.
+func SpendOf(err error) []ModelUsage {
+	var se *SpendError
+	if errors.As(err, &se) {
+		return se.Spend
+	}
+	return nil
+}
+
+// GenerationParams tune how an LLM samples its reply. Nil fields and a
+// zero MaxTokens leave the provider's default.
+type GenerationParams struct {
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   int
+	Seed        *int64
+}
+
 // InterpretOutput is the structured interpretation returned by the LLM.
 type InterpretOutput struct {
-	Text       string `json:"text"`
-	Style      string `json:"style"`
-	Disclaimer string `json:"disclaimer"`
-	Model      string `json:"-"` // set by adapter, not from LLM JSON
+	Text       string               `json:"text"`
+	Summary    string               `json:"summary"`
+	Theme      string               `json:"theme"`
+	Cards      []CardInterpretation `json:"cards"`
+	Questions  []string             `json:"questions"`
+	Style      string               `json:"style"`
+	Disclaimer string               `json:"disclaimer"`
+	Model      string               `json:"-"` // set by adapter, not from LLM JSON
+	Usage      Usage                `json:"-"` // set by adapter, summed over the calls to Model
+	Spend      []ModelUsage         `json:"-"` // set by adapter: every upstream call by model, including failed attempts
+
+	PromptVersion string         `json:"-"` // set by adapter: template set that produced the prompt
+	Safety        SafetyDecision `json:"-"` // set by the safety guardrails
+	Route         RouteDecision  `json:"-"` // set by the router, if one is configured
+}
+
+// TotalSpend returns every model's usage behind o: Spend if the adapter
+// set it, else Usage of Model.
+func (o InterpretOutput) TotalSpend() []ModelUsage {
+	if o.Spend != nil {
+		return o.Spend
+	}
+	if o.Usage == (Usage{}) {
+		return nil
+	}
+	return []ModelUsage{{Model: o.Model, Usage: o.Usage}}
+}
+
+// RouteDecision records how the router chose the interpreter that answered.
+type RouteDecision struct {
+	Target     string   // name of the target that answered
+	Reason     string   // why the first target was chosen, e.g. "lang=ru", "cards=7+", "weighted" or "order"
+	FailedOver []string // targets that failed before Target, in the order tried
+}
+
+// SafetyDecision records what the safety guardrails did with a reading.
+type SafetyDecision struct {
+	Categories []string // sensitive topics detected in the question
+	Action     string   // none, adapted, canned or replaced
+	Flags      []string // injection patterns in the question and forbidden output patterns that were caught
+}
+
+// CardInterpretation is the reading for the card drawn at Position.
+type CardInterpretation struct {
+	Position int    `json:"position"`
+	Text     string `json:"text"`
 }
 
 // Interpreter generates a tarot interpretation via an LLM.
@@ -0,0 +1,54 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+)
+
+// Reading is a drawn spread together with its conversation thread, kept so
+// follow-up questions can be asked about the same cards.
+type Reading struct {
+	ID         string
+	DeckID     string
+	Spread     domain.SpreadType
+	Lang       string
+	Style      string
+	Length     string // requested length, kept for follow-ups
+	Cards      []domain.DrawnCard
+	Clarifiers []domain.Clarifier // in the order they were drawn
+	Thread     []Turn             // the original question first, then each follow-up
+	CreatedAt  time.Time
+}
+
+// FollowUps is the number of turns after the original question.
+func (r Reading) FollowUps() int {
+	return len(r.Thread) - 1
+}
+
+// Turn is one question asked about a reading and the interpretation given.
+type Turn struct {
+	Question       string
+	Clarifiers     []domain.Clarifier // cards drawn for this turn, if any
+	Interpretation InterpretOutput
+	Model          string
+	CreatedAt      time.Time
+}
+
+// ReadingStore keeps readings for follow-up questions. Implementations
+// must be safe for concurrent use.
+type ReadingStore interface {
+	// Save stores r under r.ID, replacing any reading with the same ID.
+	Save(ctx context.Context, r Reading) error
+	// Get returns domain.ErrReadingNotFound for unknown or expired IDs.
+	Get(ctx context.Context, id string) (Reading, error)
+	// AppendTurn adds t to the reading's thread, and t.Clarifiers to its
+	// clarifiers, and returns the updated reading, or
+	// domain.ErrReadingNotFound. It returns domain.ErrFollowUpLimit
+	// without appending if the reading already has maxFollowUps follow-ups
+	// (0 = no limit), and domain.ErrTableChanged if domain.CheckClarifiers
+	// rejects t.Clarifiers. The checks and the append must be atomic, so
+	// concurrent turns cannot exceed the limit or draw the same card.
+	AppendTurn(ctx context.Context, id string, t Turn, maxFollowUps int) (Reading, error)
+}
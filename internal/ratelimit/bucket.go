@@ -0,0 +1,158 @@
+// Package ratelimit provides token-bucket request limits and a cap on
+// concurrent LLM calls.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate allows N events per Period, in bursts of up to N. The zero Rate is
+// unlimited.
+type Rate struct {
+	N      int
+	Period time.Duration
+}
+
+// ParseRate parses "N/period" such as "60/1m" or "10/s". An empty string
+// or "0" is unlimited.
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Rate{}, nil
+	}
+	rawN, rawPeriod, ok := strings.Cut(s, "/")
+	n, err := strconv.Atoi(rawN)
+	if !ok || err != nil || n < 1 {
+		return Rate{}, fmt.Errorf("want N/period such as 60/1m")
+	}
+	if rawPeriod != "" && (rawPeriod[0] < '0' || rawPeriod[0] > '9') {
+		rawPeriod = "1" + rawPeriod
+	}
+	period, err := time.ParseDuration(rawPeriod)
+	if err != nil || period <= 0 {
+		return Rate{}, fmt.Errorf("want N/period such as 60/1m")
+	}
+	return Rate{N: n, Period: period}, nil
+}
+
+func (r Rate) IsZero() bool { return r.N == 0 }
+
+func (r Rate) String() string {
+	if r.IsZero() {
+		return "0"
+	}
+	switch {
+	case r.Period%time.Hour == 0:
+		return fmt.Sprintf("%d/%dh", r.N, r.Period/time.Hour)
+	case r.Period%time.Minute == 0:
+		return fmt.Sprintf("%d/%dm", r.N, r.Period/time.Minute)
+	default:
+		return fmt.Sprintf("%d/%s", r.N, r.Period)
+	}
+}
+
+// perSecond is the refill rate.
+func (r Rate) perSecond() float64 {
+	return float64(r.N) / r.Period.Seconds()
+}
+
+// Decision is the outcome of taking a token.
+type Decision struct {
+	Allowed    bool
+	Rate       Rate          // zero if no limit applied
+	Remaining  int           // tokens left after this request
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until a token is available, if denied
+}
+
+// Buckets holds one token bucket per key, all refilling at the same rate.
+// Buckets that have refilled completely are dropped, since a new bucket is
+// the same as a full one.
+type Buckets struct {
+	mu        sync.Mutex
+	rate      Rate
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewBuckets(rate Rate) *Buckets {
+	return &Buckets{rate: rate, buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take removes a token from key's bucket if one is available.
+func (b *Buckets) Take(key string) Decision {
+	if b.rate.IsZero() {
+		return Decision{Allowed: true}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+	bk, ok := b.buckets[key]
+	if !ok {
+		bk = &bucket{tokens: float64(b.rate.N), last: now}
+		b.buckets[key] = bk
+	}
+	b.refill(bk, now)
+
+	d := Decision{Rate: b.rate}
+	if bk.tokens >= 1 {
+		bk.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = b.until(1 - bk.tokens)
+	}
+	d.Remaining = int(bk.tokens)
+	d.Reset = b.until(float64(b.rate.N) - bk.tokens)
+	return d
+}
+
+// Refund gives back a token taken from key's bucket, for a request that
+// another limit then denied.
+func (b *Buckets) Refund(key string) {
+	if b.rate.IsZero() {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bk, ok := b.buckets[key]; ok {
+		b.refill(bk, b.now())
+		bk.tokens = math.Min(float64(b.rate.N), bk.tokens+1)
+	}
+}
+
+func (b *Buckets) refill(bk *bucket, now time.Time) {
+	elapsed := now.Sub(bk.last).Seconds()
+	bk.tokens = math.Min(float64(b.rate.N), bk.tokens+elapsed*b.rate.perSecond())
+	bk.last = now
+}
+
+func (b *Buckets) until(tokens float64) time.Duration {
+	return time.Duration(tokens / b.rate.perSecond() * float64(time.Second))
+}
+
+func (b *Buckets) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < b.rate.Period {
+		return
+	}
+	b.lastSweep = now
+	for key, bk := range b.buckets {
+		b.refill(bk, now)
+		if bk.tokens >= float64(b.rate.N) {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	for in, want := range map[string]Rate{
+		"":       {},
+		"0":      {},
+		"60/1m":  {N: 60, Period: time.Minute},
+		"10/s":   {N: 10, Period: time.Second},
+		"100/2h": {N: 100, Period: 2 * time.Hour},
+	} {
+		got, err := ParseRate(in)
+		if err != nil || got != want {
+			t.Errorf("ParseRate(%q) = %v, %v; want %v", in, got, err, want)
+		}
+		if again, _ := ParseRate(got.String()); again != got {
+			t.Errorf("%v does not round-trip through %q", got, got.String())
+		}
+	}
+	for _, in := range []string{"60", "x/1m", "-1/1m", "5/0s", "5/soon"} {
+		if _, err := ParseRate(in); err == nil {
+			t.Errorf("ParseRate(%q) accepted", in)
+		}
+	}
+}
+
+func TestBuckets_TakeAndRefill(t *testing.T) {
+	b := NewBuckets(Rate{N: 2, Period: 2 * time.Second})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	if d := b.Take("a"); !d.Allowed || d.Remaining != 1 {
+		t.Fatalf("first take = %+v", d)
+	}
+	if d := b.Take("a"); !d.Allowed || d.Remaining != 0 || d.Reset != 2*time.Second {
+		t.Fatalf("second take = %+v", d)
+	}
+	d := b.Take("a")
+	if d.Allowed || d.RetryAfter != time.Second {
+		t.Fatalf("third take = %+v, want denied with 1s retry", d)
+	}
+	if d := b.Take("b"); !d.Allowed {
+		t.Error("keys share a bucket")
+	}
+
+	now = now.Add(time.Second)
+	if d := b.Take("a"); !d.Allowed {
+		t.Errorf("no refill after 1s: %+v", d)
+	}
+}
+
+func TestBuckets_SweepDropsFullBuckets(t *testing.T) {
+	b := NewBuckets(Rate{N: 1, Period: time.Second})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	b.Take("a")
+	now = now.Add(2 * time.Second)
+	b.Take("b")
+	if _, ok := b.buckets["a"]; ok {
+		t.Error("idle bucket kept")
+	}
+}
+
+func TestBuckets_Unlimited(t *testing.T) {
+	b := NewBuckets(Rate{})
+	for range 100 {
+		if d := b.Take("a"); !d.Allowed || !d.Rate.IsZero() {
+			t.Fatalf("unlimited bucket denied: %+v", d)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Concurrency caps the number of interpreter calls in flight. Calls over
+// the cap fail at once with domain.ErrRateLimited rather than queueing
+// behind slow LLM responses.
+type Concurrency struct {
+	mu       sync.Mutex
+	max      int // 0 = unlimited
+	inFlight int
+}
+
+func NewConcurrency(max int) *Concurrency {
+	return &Concurrency{max: max}
+}
+
+// SetMax changes the cap. Calls already in flight are not affected.
+func (c *Concurrency) SetMax(max int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.max = max
+}
+
+func (c *Concurrency) acquire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.max > 0 && c.inFlight >= c.max {
+		return false
+	}
+	c.inFlight++
+	return true
+}
+
+func (c *Concurrency) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight--
+}
+
+// Wrap returns next limited by c. Interpreters wrapped by the same
+// Concurrency share its cap.
+func (c *Concurrency) Wrap(next ports.Interpreter) ports.Interpreter {
+	return &limitedInterpreter{limit: c, next: next}
+}
+
+type limitedInterpreter struct {
+	limit *Concurrency
+	next  ports.Interpreter
+}
+
+func (l *limitedInterpreter) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	if !l.limit.acquire() {
+		return ports.InterpretOutput{}, fmt.Errorf("%w: too many LLM calls in flight", domain.ErrRateLimited)
+	}
+	defer l.limit.release()
+	return l.next.Interpret(ctx, in)
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// Limits configures a Limiter. Routes override PerIP for requests to a
+// route, each with its own buckets; a zero Rate there exempts the route.
+type Limits struct {
+	Global Rate
+	PerIP  Rate
+	Routes map[string]Rate
+}
+
+// Limiter applies a global limit and per-client limits to requests.
+type Limiter struct {
+	mu     sync.RWMutex
+	limits Limits
+	global *Buckets
+	perIP  *Buckets
+	routes map[string]*Buckets
+}
+
+func NewLimiter(limits Limits) *Limiter {
+	l := &Limiter{}
+	l.SetLimits(limits)
+	return l
+}
+
+// SetLimits replaces the limits. Buckets whose rate is unchanged keep
+// their state.
+func (l *Limiter) SetLimits(limits Limits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.global == nil || l.limits.Global != limits.Global {
+		l.global = NewBuckets(limits.Global)
+	}
+	if l.perIP == nil || l.limits.PerIP != limits.PerIP {
+		l.perIP = NewBuckets(limits.PerIP)
+	}
+	routes := make(map[string]*Buckets, len(limits.Routes))
+	for route, rate := range limits.Routes {
+		if b, ok := l.routes[route]; ok && l.limits.Routes[route] == rate {
+			routes[route] = b
+			continue
+		}
+		routes[route] = NewBuckets(rate)
+	}
+	l.routes = routes
+	l.limits = Limits{Global: limits.Global, PerIP: limits.PerIP, Routes: maps.Clone(limits.Routes)}
+}
+
+// Allow takes a token for a request to route from ip. The decision
+// reported is the most restrictive of the limits that applied. A request
+// the global limit denies costs the client nothing, so clients are not
+// locked out by traffic from others.
+func (l *Limiter) Allow(route, ip string) Decision {
+	l.mu.RLock()
+	client, ok := l.routes[route]
+	if !ok {
+		client = l.perIP
+	}
+	global := l.global
+	l.mu.RUnlock()
+
+	d := client.Take(ip)
+	if !d.Allowed {
+		return d
+	}
+	g := global.Take("")
+	if !g.Allowed {
+		client.Refund(ip)
+	}
+	return tighter(d, g)
+}
+
+func tighter(a, b Decision) Decision {
+	switch {
+	case b.Rate.IsZero():
+		return a
+	case a.Rate.IsZero():
+		return b
+	case !b.Allowed:
+		return b
+	case b.Remaining < a.Remaining:
+		return b
+	}
+	return a
+}
+
+// RetryAfterSeconds rounds d up to whole seconds for Retry-After and
+// RateLimit-Reset headers.
+func RetryAfterSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+func TestLimiter_RouteOverridesPerIP(t *testing.T) {
+	l := NewLimiter(Limits{
+		PerIP:  Rate{N: 5, Period: time.Minute},
+		Routes: map[string]Rate{"/v1/tarot": {N: 1, Period: time.Minute}, "/v1/styles": {}},
+	})
+
+	if d := l.Allow("/v1/tarot", "1.2.3.4"); !d.Allowed || d.Rate.N != 1 {
+		t.Fatalf("first tarot = %+v", d)
+	}
+	if d := l.Allow("/v1/tarot", "1.2.3.4"); d.Allowed {
+		t.Error("route limit not applied")
+	}
+	if d := l.Allow("/v1/tarot", "5.6.7.8"); !d.Allowed {
+		t.Error("route limit shared between clients")
+	}
+	if d := l.Allow("/v1/readings", "1.2.3.4"); !d.Allowed || d.Rate.N != 5 {
+		t.Errorf("default per-IP limit = %+v", d)
+	}
+	for range 10 {
+		if d := l.Allow("/v1/styles", "1.2.3.4"); !d.Allowed {
+			t.Fatal("exempt route limited")
+		}
+	}
+}
+
+func TestLimiter_Global(t *testing.T) {
+	l := NewLimiter(Limits{Global: Rate{N: 2, Period: time.Minute}, PerIP: Rate{N: 10, Period: time.Minute}})
+
+	d := l.Allow("/v1/tarot", "a")
+	if !d.Allowed || d.Rate.N != 2 || d.Remaining != 1 {
+		t.Errorf("decision should report the tighter global limit: %+v", d)
+	}
+	l.Allow("/v1/tarot", "b")
+	if d := l.Allow("/v1/tarot", "c"); d.Allowed {
+		t.Error("global limit not applied")
+	}
+}
+
+func TestLimiter_GlobalDenialRefundsClient(t *testing.T) {
+	l := NewLimiter(Limits{Global: Rate{N: 1, Period: time.Hour}, PerIP: Rate{N: 2, Period: time.Hour}})
+	l.Allow("/v1/tarot", "a")
+
+	for range 3 {
+		if d := l.Allow("/v1/tarot", "b"); d.Allowed {
+			t.Fatal("global limit not applied")
+		}
+	}
+	l.SetLimits(Limits{PerIP: Rate{N: 2, Period: time.Hour}})
+	if d := l.Allow("/v1/tarot", "b"); !d.Allowed || d.Remaining != 1 {
+		t.Errorf("requests the global limit denied used up the client's tokens: %+v", d)
+	}
+}
+
+func TestLimiter_SetLimitsKeepsUnchangedBuckets(t *testing.T) {
+	limits := Limits{PerIP: Rate{N: 1, Period: time.Hour}}
+	l := NewLimiter(limits)
+	l.Allow("/v1/tarot", "a")
+
+	limits.Global = Rate{N: 100, Period: time.Hour}
+	l.SetLimits(limits)
+	if d := l.Allow("/v1/tarot", "a"); d.Allowed {
+		t.Error("reload reset the per-IP bucket")
+	}
+}
+
+type blockingInterpreter struct{ release chan struct{} }
+
+func (b blockingInterpreter) Interpret(context.Context, ports.InterpretInput) (ports.InterpretOutput, error) {
+	<-b.release
+	return ports.InterpretOutput{}, nil
+}
+
+func TestConcurrency(t *testing.T) {
+	c := NewConcurrency(1)
+	next := blockingInterpreter{release: make(chan struct{})}
+	a, b := c.Wrap(next), c.Wrap(next)
+
+	done := make(chan error)
+	go func() {
+		_, err := a.Interpret(context.Background(), ports.InterpretInput{})
+		done <- err
+	}()
+	// Wait until the first call holds the slot.
+	for !func() bool { c.mu.Lock(); defer c.mu.Unlock(); return c.inFlight == 1 }() {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := b.Interpret(context.Background(), ports.InterpretInput{}); !errors.Is(err, domain.ErrRateLimited) {
+		t.Errorf("second call = %v, want ErrRateLimited", err)
+	}
+	close(next.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Interpret(context.Background(), ports.InterpretInput{}); err != nil {
+		t.Errorf("call after release = %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+// Package reload applies configuration changes to a running server.
+//
+// Only settings marked reloadable in internal/config change; the rest are
+// reported as needing a restart. Components built from reloadable settings
+// are swapped atomically, so requests already in flight finish on the
+// components they started with.
+package reload
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/randomtoy/taas-go/internal/config"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Interpreter forwards to an implementation that can be replaced while
+// serving.
+type Interpreter struct {
+	current atomic.Pointer[ports.Interpreter]
+}
+
+func NewInterpreter(initial ports.Interpreter) *Interpreter {
+	i := &Interpreter{}
+	i.Swap(initial)
+	return i
+}
+
+// Swap makes next serve every call that starts from now on.
+func (i *Interpreter) Swap(next ports.Interpreter) {
+	i.current.Store(&next)
+}
+
+func (i *Interpreter) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	return (*i.current.Load()).Interpret(ctx, in)
+}
+
+// Result describes a reload.
+type Result struct {
+	Applied         []config.Change `json:"applied"`
+	RestartRequired []config.Change `json:"restart_required"`
+}
+
+// Reloader re-reads the configuration and applies what changed.
+type Reloader struct {
+	mu      sync.Mutex
+	current config.Config
+	load    func() (config.Config, error)
+	apply   func(config.Config) error
+	logger  *slog.Logger
+}
+
+// NewReloader returns a reloader starting from current. load reads the
+// configuration again; apply rebuilds and swaps in the components that
+// depend on reloadable settings, and must leave them untouched if it
+// fails.
+func NewReloader(current config.Config, load func() (config.Config, error), apply func(config.Config) error, logger *slog.Logger) *Reloader {
+	return &Reloader{current: current, load: load, apply: apply, logger: logger}
+}
+
+// Reload reads the configuration and applies its reloadable changes. The
+// resulting configuration is validated before it is applied; on error the
+// running configuration is kept.
+func (r *Reloader) Reload() (Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		r.logger.Error("config reload failed", "error", err)
+		return Result{}, err
+	}
+
+	res := Result{Applied: []config.Change{}, RestartRequired: []config.Change{}}
+	for _, ch := range config.Diff(r.current, next) {
+		if ch.Reloadable {
+			res.Applied = append(res.Applied, ch)
+		} else {
+			res.RestartRequired = append(res.RestartRequired, ch)
+		}
+	}
+
+	// Apply even without changes: files behind unchanged settings, such
+	// as the prompt templates, may have been edited.
+	effective := config.Reload(r.current, next)
+	// next was valid on its own, but mixed with settings that only change
+	// on restart it may not be, e.g. a cleared OPENROUTER_API_KEY while
+	// LLM_PROVIDER is still openrouter.
+	if err := effective.Validate(); err != nil {
+		r.logger.Error("config reload failed", "error", err)
+		return Result{}, err
+	}
+	if err := r.apply(effective); err != nil {
+		r.logger.Error("config reload failed", "error", err)
+		return Result{}, err
+	}
+	r.current = effective
+
+	for _, ch := range res.Applied {
+		r.logger.Info("config changed", "key", ch.Key, "old", ch.Old, "new", ch.New)
+	}
+	for _, ch := range res.RestartRequired {
+		r.logger.Warn("config change needs a restart", "key", ch.Key, "old", ch.Old, "new", ch.New)
+	}
+	r.logger.Info("config reloaded", "applied", len(res.Applied), "restart_required", len(res.RestartRequired))
+	return res, nil
+}
+
+// Watch reloads on every signal until ctx is done.
+func (r *Reloader) Watch(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			_, _ = r.Reload()
+		}
+	}
+}
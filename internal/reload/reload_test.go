@@ -0,0 +1,145 @@
+package reload_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randomtoy/taas-go/internal/config"
+	"github.com/randomtoy/taas-go/internal/ports"
+	"github.com/randomtoy/taas-go/internal/reload"
+)
+
+type namedInterpreter struct {
+	name    string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (n *namedInterpreter) Interpret(context.Context, ports.InterpretInput) (ports.InterpretOutput, error) {
+	if n.started != nil {
+		close(n.started)
+		<-n.release
+	}
+	return ports.InterpretOutput{Model: n.name}, nil
+}
+
+func TestInterpreter_InFlightCallsKeepOldImplementation(t *testing.T) {
+	old := &namedInterpreter{name: "old", started: make(chan struct{}), release: make(chan struct{})}
+	i := reload.NewInterpreter(old)
+
+	done := make(chan string)
+	go func() {
+		out, _ := i.Interpret(context.Background(), ports.InterpretInput{})
+		done <- out.Model
+	}()
+	<-old.started
+
+	i.Swap(&namedInterpreter{name: "new"})
+	if out, _ := i.Interpret(context.Background(), ports.InterpretInput{}); out.Model != "new" {
+		t.Errorf("call after swap used %q", out.Model)
+	}
+	close(old.release)
+	if got := <-done; got != "old" {
+		t.Errorf("in-flight call finished on %q", got)
+	}
+}
+
+// loadConfig returns a valid configuration to reload from.
+func loadConfig(t *testing.T) config.Config {
+	t.Helper()
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	c, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func newReloader(current config.Config, next *config.Config, loadErr *error, applied *[]config.Config) *reload.Reloader {
+	return reload.NewReloader(current,
+		func() (config.Config, error) { return *next, *loadErr },
+		func(c config.Config) error {
+			*applied = append(*applied, c)
+			return nil
+		},
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+}
+
+func TestReloader_AppliesOnlyReloadableSettings(t *testing.T) {
+	current := loadConfig(t)
+	current.HTTPAddr, current.LLMModel, current.LLMTimeout = ":8080", "a", time.Second
+	next := current
+	next.HTTPAddr, next.LLMModel = ":9090", "b"
+	var loadErr error
+	var applied []config.Config
+	r := newReloader(current, &next, &loadErr, &applied)
+
+	res, err := r.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Applied) != 1 || res.Applied[0].Key != "llm.model" {
+		t.Errorf("Applied = %+v", res.Applied)
+	}
+	if len(res.RestartRequired) != 1 || res.RestartRequired[0].Key != "server.addr" {
+		t.Errorf("RestartRequired = %+v", res.RestartRequired)
+	}
+	if len(applied) != 1 || applied[0].LLMModel != "b" || applied[0].HTTPAddr != ":8080" {
+		t.Errorf("applied %+v", applied)
+	}
+
+	// The restart-only change is still pending; the model is not new.
+	res, _ = r.Reload()
+	if len(res.Applied) != 0 || len(res.RestartRequired) != 1 {
+		t.Errorf("second reload = %+v", res)
+	}
+}
+
+func TestReloader_KeepsConfigOnError(t *testing.T) {
+	current := loadConfig(t)
+	current.LLMModel = "a"
+	next := current
+	next.LLMModel = "b"
+	loadErr := errors.New("invalid LLM_TIMEOUT")
+	var applied []config.Config
+	r := newReloader(current, &next, &loadErr, &applied)
+
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(applied) != 0 {
+		t.Fatal("applied an invalid config")
+	}
+
+	loadErr = nil
+	if res, err := r.Reload(); err != nil || len(res.Applied) != 1 {
+		t.Errorf("reload after fix: %+v, %v", res, err)
+	}
+}
+
+// A configuration that loads on its own can still be invalid once merged
+// with the settings that only change on restart.
+func TestReloader_RejectsInvalidMergedConfig(t *testing.T) {
+	current := loadConfig(t)
+	next := current
+	next.LLMProvider, next.OpenAIBaseURL, next.OpenRouterAPIKey = "openai", "http://localhost:8000/v1", ""
+	if err := next.Validate(); err != nil {
+		t.Fatalf("next should be valid on its own: %v", err)
+	}
+	var loadErr error
+	var applied []config.Config
+	r := newReloader(current, &next, &loadErr, &applied)
+
+	if _, err := r.Reload(); err == nil || !strings.Contains(err.Error(), "OPENROUTER_API_KEY") {
+		t.Fatalf("expected the missing OpenRouter key to be reported, got %v", err)
+	}
+	if len(applied) != 0 {
+		t.Fatal("applied an invalid config")
+	}
+}
@@ -0,0 +1,202 @@
+// Package routing sends each reading to one of several interpreters, such
+// as LLMs from different providers and the template interpreter, chosen by
+// language, spread size or weight, and falls back to the others in order
+// when the chosen one fails.
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Policy chooses the first target for readings that no rule matches.
+type Policy string
+
+const (
+	// PolicyFallback tries targets in the configured order.
+	PolicyFallback Policy = "fallback"
+	// PolicyWeighted picks the first target at random by weight.
+	PolicyWeighted Policy = "weighted"
+)
+
+// ParsePolicy validates a configured policy name.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyFallback, PolicyWeighted:
+		return p, nil
+	default:
+		return "", fmt.Errorf("want fallback or weighted")
+	}
+}
+
+// TemplateProvider names the built-in non-LLM interpreter as a target.
+const TemplateProvider = "template"
+
+// ReplayProvider names the interpreter that serves recorded fixtures.
+const ReplayProvider = "replay"
+
+// Providers that can back a target.
+var Providers = []string{"openrouter", "openai", "anthropic", TemplateProvider, ReplayProvider}
+
+// Spec names the provider and model behind a target, written
+// "provider:model", e.g. "openrouter:qwen/qwen3-4b:free", or "template" or
+// "replay".
+type Spec struct {
+	Provider string
+	Model    string
+}
+
+func ParseSpec(s string) (Spec, error) {
+	provider, model, _ := strings.Cut(strings.TrimSpace(s), ":")
+	if !slices.Contains(Providers, provider) {
+		return Spec{}, fmt.Errorf("%q: want provider:model with provider one of %s", s, strings.Join(Providers, ", "))
+	}
+	if provider == TemplateProvider || provider == ReplayProvider {
+		if model != "" {
+			return Spec{}, fmt.Errorf("%q: the %s target takes no model", s, provider)
+		}
+		return Spec{Provider: provider}, nil
+	}
+	if model == "" {
+		return Spec{}, fmt.Errorf("%q: model is missing", s)
+	}
+	return Spec{Provider: provider, Model: model}, nil
+}
+
+func (s Spec) String() string {
+	if s.Model == "" {
+		return s.Provider
+	}
+	return s.Provider + ":" + s.Model
+}
+
+// CardRange matches readings with Min to Max cards; Max 0 means no upper
+// bound. It is written "1-3", "5" or "7+".
+type CardRange struct {
+	Min, Max int
+}
+
+func ParseCardRange(s string) (CardRange, error) {
+	s = strings.TrimSpace(s)
+	bad := fmt.Errorf("%q: want a card count such as 1-3, 5 or 7+", s)
+	if rawMin, ok := strings.CutSuffix(s, "+"); ok {
+		n, err := strconv.Atoi(rawMin)
+		if err != nil || n < 1 {
+			return CardRange{}, bad
+		}
+		return CardRange{Min: n}, nil
+	}
+	rawMin, rawMax, isRange := strings.Cut(s, "-")
+	lo, err := strconv.Atoi(rawMin)
+	if err != nil || lo < 1 {
+		return CardRange{}, bad
+	}
+	hi := lo
+	if isRange {
+		if hi, err = strconv.Atoi(rawMax); err != nil || hi < lo {
+			return CardRange{}, bad
+		}
+	}
+	return CardRange{Min: lo, Max: hi}, nil
+}
+
+func (r CardRange) contains(n int) bool {
+	return n >= r.Min && (r.Max == 0 || n <= r.Max)
+}
+
+func (r CardRange) String() string {
+	switch {
+	case r.Max == 0:
+		return strconv.Itoa(r.Min) + "+"
+	case r.Max == r.Min:
+		return strconv.Itoa(r.Min)
+	default:
+		return strconv.Itoa(r.Min) + "-" + strconv.Itoa(r.Max)
+	}
+}
+
+// Config describes the targets and how readings are routed among them.
+// Routing is off when Targets is empty.
+type Config struct {
+	Targets map[string]Spec   // by target name
+	Order   []string          // fallback order; unlisted targets follow, sorted by name
+	Policy  Policy            // how the first target is picked when no rule matches
+	Weights map[string]int    // for PolicyWeighted; targets without one are only fallbacks
+	ByLang  map[string]string // language code to target, e.g. "ru" to a model good at Russian
+	ByCards map[string]string // card range, e.g. "7+", to target
+}
+
+// Enabled reports whether any targets are configured.
+func (c Config) Enabled() bool { return len(c.Targets) > 0 }
+
+// Validate checks that every name refers to a target and that a weighted
+// policy has something to pick.
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	var errs []error
+	check := func(what, name string) {
+		if _, ok := c.Targets[name]; !ok {
+			errs = append(errs, fmt.Errorf("%s refers to unknown target %q", what, name))
+		}
+	}
+	for _, name := range c.Order {
+		check("order", name)
+	}
+	for _, name := range sortedKeys(c.Weights) {
+		check("weights", name)
+		if c.Weights[name] < 0 {
+			errs = append(errs, fmt.Errorf("weight of %q must not be negative", name))
+		}
+	}
+	for _, lang := range sortedKeys(c.ByLang) {
+		check("by_lang "+lang, c.ByLang[lang])
+	}
+	for _, raw := range sortedKeys(c.ByCards) {
+		if _, err := ParseCardRange(raw); err != nil {
+			errs = append(errs, err)
+		}
+		check("by_cards "+raw, c.ByCards[raw])
+	}
+	if c.Policy == PolicyWeighted && !slices.ContainsFunc(sortedKeys(c.Weights), func(name string) bool { return c.Weights[name] > 0 }) {
+		errs = append(errs, fmt.Errorf("the weighted policy needs a positive weight for at least one target"))
+	}
+	return errors.Join(errs...)
+}
+
+// UsedProviders lists the providers behind the targets, sorted.
+func (c Config) UsedProviders() []string {
+	var out []string
+	for _, spec := range c.Targets {
+		if !slices.Contains(out, spec.Provider) {
+			out = append(out, spec.Provider)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// order returns the fallback order: Order, then the remaining targets by
+// name.
+func (c Config) order() []string {
+	out := slices.Clone(c.Order)
+	for _, name := range sortedKeys(c.Targets) {
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
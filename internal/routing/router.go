@@ -0,0 +1,179 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/randomtoy/taas-go/internal/domain"
+	"github.com/randomtoy/taas-go/internal/health"
+	"github.com/randomtoy/taas-go/internal/ports"
+)
+
+// Target is an interpreter the router can send readings to.
+type Target struct {
+	Interpreter ports.Interpreter
+	// Checker reports whether the target's upstream is reachable; nil for
+	// targets that are always available, such as the template interpreter.
+	Checker health.Checker
+}
+
+type cardRule struct {
+	cards  CardRange
+	target string
+}
+
+// Router implements ports.Interpreter over named targets. Rules pick the
+// first target by language, then by the number of cards; otherwise the
+// policy does. If a target fails, the rest are tried in fallback order.
+type Router struct {
+	targets map[string]Target
+	order   []string
+	policy  Policy
+	weights map[string]int
+	byLang  map[string]string
+	byCards []cardRule
+	rng     domain.RNG
+	logger  *slog.Logger
+}
+
+// NewRouter returns a router over targets, which must hold every target
+// named in cfg.
+func NewRouter(cfg Config, targets map[string]Target, rng domain.RNG, logger *slog.Logger) (*Router, error) {
+	if !cfg.Enabled() {
+		return nil, fmt.Errorf("no routing targets configured")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(cfg.Targets) {
+		if _, ok := targets[name]; !ok {
+			return nil, fmt.Errorf("no interpreter for target %q", name)
+		}
+	}
+
+	r := &Router{
+		targets: targets,
+		order:   cfg.order(),
+		policy:  cfg.Policy,
+		weights: cfg.Weights,
+		byLang:  make(map[string]string, len(cfg.ByLang)),
+		rng:     rng,
+		logger:  logger,
+	}
+	for lang, target := range cfg.ByLang {
+		r.byLang[strings.ToLower(lang)] = target
+	}
+	for raw, target := range cfg.ByCards {
+		cards, _ := ParseCardRange(raw) // checked by Validate
+		r.byCards = append(r.byCards, cardRule{cards: cards, target: target})
+	}
+	// Overlapping ranges resolve the same way on every run.
+	slices.SortFunc(r.byCards, func(a, b cardRule) int {
+		if a.cards.Min != b.cards.Min {
+			return a.cards.Min - b.cards.Min
+		}
+		return a.cards.Max - b.cards.Max
+	})
+	return r, nil
+}
+
+func (r *Router) Interpret(ctx context.Context, in ports.InterpretInput) (ports.InterpretOutput, error) {
+	first, reason := r.choose(in)
+	attempts := append([]string{first}, slices.DeleteFunc(slices.Clone(r.order), func(name string) bool { return name == first })...)
+
+	var failed []string
+	var lastErr error
+	// Tokens spent by failed targets are passed on with the result.
+	var spend []ports.ModelUsage
+	for _, name := range attempts {
+		out, err := r.targets[name].Interpreter.Interpret(ctx, in)
+		if err == nil {
+			out.Route = ports.RouteDecision{Target: name, Reason: reason, FailedOver: failed}
+			if spend != nil {
+				out.Spend = append(spend, out.TotalSpend()...)
+			}
+			r.logger.InfoContext(ctx, "reading routed",
+				"target", name, "reason", reason, "failed_over", failed, "model", out.Model)
+			return out, nil
+		}
+		spend = append(spend, ports.SpendOf(err)...)
+		if ctx.Err() != nil {
+			return ports.InterpretOutput{}, withSpend(err, spend)
+		}
+		r.logger.WarnContext(ctx, "route target failed, trying next", "target", name, "reason", reason, "error", err)
+		failed = append(failed, name)
+		lastErr = err
+	}
+	return ports.InterpretOutput{}, withSpend(lastErr, spend)
+}
+
+// withSpend makes err carry spend, the tokens of every failed target.
+func withSpend(err error, spend []ports.ModelUsage) error {
+	if spend == nil {
+		return err
+	}
+	return &ports.SpendError{Spend: spend, Err: err}
+}
+
+// choose returns the first target for in and why it was chosen.
+func (r *Router) choose(in ports.InterpretInput) (target, reason string) {
+	lang := strings.ToLower(in.Lang)
+	if t, ok := r.byLang[lang]; ok {
+		return t, "lang=" + lang
+	}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		if t, ok := r.byLang[base]; ok {
+			return t, "lang=" + base
+		}
+	}
+
+	// Clarifier inputs carry the original spread in Context.
+	n := len(in.Cards) + len(in.Context)
+	for _, rule := range r.byCards {
+		if rule.cards.contains(n) {
+			return rule.target, "cards=" + rule.cards.String()
+		}
+	}
+
+	if r.policy == PolicyWeighted {
+		return r.pickWeighted(), "weighted"
+	}
+	return r.order[0], "order"
+}
+
+func (r *Router) pickWeighted() string {
+	total := 0
+	for _, name := range r.order {
+		total += r.weights[name]
+	}
+	k := r.rng.Intn(total)
+	for _, name := range r.order {
+		if k < r.weights[name] {
+			return name
+		}
+		k -= r.weights[name]
+	}
+	return r.order[0]
+}
+
+// Check passes when any target is available, since the router can then
+// still answer.
+func (r *Router) Check(ctx context.Context) error {
+	var errs []error
+	for _, name := range r.order {
+		t := r.targets[name]
+		if t.Checker == nil {
+			return nil
+		}
+		err := t.Checker.Check(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return errors.Join(errs...)
+}
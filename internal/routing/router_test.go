@@ -0,0 +1,279 @@
+package routing_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/randomtoy/taas-go/internal/health"
+	"github.com/randomtoy/taas-go/internal/ports"
+	"github.com/randomtoy/taas-go/internal/routing"
+)
+
+type stubInterpreter struct {
+	model string
+	err   error
+	calls int
+}
+
+func (s *stubInterpreter) Interpret(context.Context, ports.InterpretInput) (ports.InterpretOutput, error) {
+	s.calls++
+	if s.err != nil {
+		return ports.InterpretOutput{}, s.err
+	}
+	return ports.InterpretOutput{Text: "ok", Model: s.model}, nil
+}
+
+// fixedRNG returns its values in turn.
+type fixedRNG []int
+
+func (r *fixedRNG) Intn(int) int {
+	v := (*r)[0]
+	*r = (*r)[1:]
+	return v
+}
+
+func cards(n int) []ports.CardInput {
+	return make([]ports.CardInput, n)
+}
+
+func newRouter(t *testing.T, cfg routing.Config, stubs map[string]*stubInterpreter, rng *fixedRNG) *routing.Router {
+	t.Helper()
+	cfg.Targets = make(map[string]routing.Spec)
+	targets := make(map[string]routing.Target)
+	for name, s := range stubs {
+		cfg.Targets[name] = routing.Spec{Provider: "openai", Model: s.model}
+		targets[name] = routing.Target{Interpreter: s}
+	}
+	if rng == nil {
+		rng = &fixedRNG{}
+	}
+	r, err := routing.NewRouter(cfg, targets, rng, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRouter_FallbackOrder(t *testing.T) {
+	stubs := map[string]*stubInterpreter{
+		"remote": {model: "remote-model", err: errors.New("upstream down")},
+		"local":  {model: "local-model"},
+		"canned": {model: "template"},
+	}
+	r := newRouter(t, routing.Config{Policy: routing.PolicyFallback, Order: []string{"remote", "local"}}, stubs, nil)
+
+	out, err := r.Interpret(context.Background(), ports.InterpretInput{Lang: "en", Cards: cards(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ports.RouteDecision{Target: "local", Reason: "order", FailedOver: []string{"remote"}}
+	if !reflect.DeepEqual(out.Route, want) || out.Model != "local-model" {
+		t.Errorf("route = %+v, model %s", out.Route, out.Model)
+	}
+	if stubs["canned"].calls != 0 {
+		t.Error("unlisted target tried before the listed ones succeeded")
+	}
+}
+
+func TestRouter_AllFail(t *testing.T) {
+	errLast := errors.New("last")
+	stubs := map[string]*stubInterpreter{
+		"a": {err: errors.New("first")},
+		"b": {err: errLast},
+	}
+	r := newRouter(t, routing.Config{Policy: routing.PolicyFallback, Order: []string{"a", "b"}}, stubs, nil)
+
+	if _, err := r.Interpret(context.Background(), ports.InterpretInput{}); !errors.Is(err, errLast) {
+		t.Errorf("err = %v, want the last target's error", err)
+	}
+}
+
+func TestRouter_PassesOnSpendOfFailedTargets(t *testing.T) {
+	spent := &ports.SpendError{Spend: []ports.ModelUsage{{Model: "a", Usage: ports.Usage{TotalTokens: 30}}}, Err: errors.New("bad reply")}
+	stubs := map[string]*stubInterpreter{"a": {err: spent}, "b": {model: "b"}, "c": {err: spent}}
+
+	r := newRouter(t, routing.Config{Policy: routing.PolicyFallback, Order: []string{"a", "b"}}, stubs, nil)
+	out, err := r.Interpret(context.Background(), ports.InterpretInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := spent.Spend; !reflect.DeepEqual(out.Spend, want) {
+		t.Errorf("Spend = %+v, want %+v", out.Spend, want)
+	}
+
+	r = newRouter(t, routing.Config{Policy: routing.PolicyFallback, Order: []string{"a", "c"}}, map[string]*stubInterpreter{"a": stubs["a"], "c": stubs["c"]}, nil)
+	_, err = r.Interpret(context.Background(), ports.InterpretInput{})
+	if got := ports.SpendOf(err); len(got) != 2 {
+		t.Errorf("SpendOf(err) = %+v, want both targets' spend", got)
+	}
+}
+
+func TestRouter_ByLang(t *testing.T) {
+	stubs := map[string]*stubInterpreter{
+		"default": {model: "a"},
+		"russian": {model: "b"},
+	}
+	r := newRouter(t, routing.Config{
+		Policy: routing.PolicyFallback,
+		Order:  []string{"default", "russian"},
+		ByLang: map[string]string{"ru": "russian"},
+	}, stubs, nil)
+
+	out, err := r.Interpret(context.Background(), ports.InterpretInput{Lang: "ru-RU", Cards: cards(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Route.Target != "russian" || out.Route.Reason != "lang=ru" {
+		t.Errorf("route = %+v", out.Route)
+	}
+
+	out, _ = r.Interpret(context.Background(), ports.InterpretInput{Lang: "en", Cards: cards(3)})
+	if out.Route.Target != "default" {
+		t.Errorf("route = %+v", out.Route)
+	}
+}
+
+func TestRouter_ByCards(t *testing.T) {
+	stubs := map[string]*stubInterpreter{
+		"small": {model: "a"},
+		"large": {model: "b"},
+	}
+	r := newRouter(t, routing.Config{
+		Policy:  routing.PolicyFallback,
+		Order:   []string{"small", "large"},
+		ByCards: map[string]string{"7+": "large"},
+	}, stubs, nil)
+
+	out, _ := r.Interpret(context.Background(), ports.InterpretInput{Cards: cards(10)})
+	if out.Route.Target != "large" || out.Route.Reason != "cards=7+" {
+		t.Errorf("route = %+v", out.Route)
+	}
+
+	// A clarifier for a ten-card spread counts the spread too.
+	out, _ = r.Interpret(context.Background(), ports.InterpretInput{Cards: cards(1), Context: cards(10)})
+	if out.Route.Target != "large" {
+		t.Errorf("clarifier route = %+v", out.Route)
+	}
+
+	out, _ = r.Interpret(context.Background(), ports.InterpretInput{Cards: cards(3)})
+	if out.Route.Target != "small" || out.Route.Reason != "order" {
+		t.Errorf("route = %+v", out.Route)
+	}
+}
+
+func TestRouter_Weighted(t *testing.T) {
+	stubs := map[string]*stubInterpreter{
+		"a":      {model: "a"},
+		"b":      {model: "b"},
+		"canned": {model: "template"},
+	}
+	rng := fixedRNG{0, 1, 3}
+	r := newRouter(t, routing.Config{
+		Policy:  routing.PolicyWeighted,
+		Order:   []string{"a", "b", "canned"},
+		Weights: map[string]int{"a": 1, "b": 3},
+	}, stubs, &rng)
+
+	var got []string
+	for range 3 {
+		out, err := r.Interpret(context.Background(), ports.InterpretInput{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Route.Reason != "weighted" {
+			t.Errorf("reason = %q", out.Route.Reason)
+		}
+		got = append(got, out.Route.Target)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b", "b"}) {
+		t.Errorf("targets = %v", got)
+	}
+	if stubs["canned"].calls != 0 {
+		t.Error("target without weight was picked")
+	}
+}
+
+func TestRouter_Check(t *testing.T) {
+	down := health.CheckerFunc(func(context.Context) error { return errors.New("down") })
+	up := health.CheckerFunc(func(context.Context) error { return nil })
+	cfg := routing.Config{
+		Targets: map[string]routing.Spec{"a": {Provider: "openai", Model: "a"}, "b": {Provider: "anthropic", Model: "b"}},
+		Policy:  routing.PolicyFallback,
+	}
+	stub := &stubInterpreter{}
+
+	r, _ := routing.NewRouter(cfg, map[string]routing.Target{"a": {Interpreter: stub, Checker: down}, "b": {Interpreter: stub, Checker: up}}, &fixedRNG{}, slog.Default())
+	if err := r.Check(context.Background()); err != nil {
+		t.Errorf("one target up: %v", err)
+	}
+
+	r, _ = routing.NewRouter(cfg, map[string]routing.Target{"a": {Interpreter: stub, Checker: down}, "b": {Interpreter: stub, Checker: down}}, &fixedRNG{}, slog.Default())
+	if err := r.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "a: down") {
+		t.Errorf("all down: %v", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := routing.Config{
+		Targets: map[string]routing.Spec{"a": {Provider: "template"}},
+		Policy:  routing.PolicyWeighted,
+		Order:   []string{"b"},
+		ByLang:  map[string]string{"ru": "c"},
+		ByCards: map[string]string{"x": "a"},
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		`order refers to unknown target "b"`,
+		`by_lang ru refers to unknown target "c"`,
+		`"x": want a card count`,
+		"needs a positive weight",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	for in, want := range map[string]routing.Spec{
+		"openrouter:qwen/qwen3-4b:free": {Provider: "openrouter", Model: "qwen/qwen3-4b:free"},
+		"anthropic:claude-sonnet-4-5":   {Provider: "anthropic", Model: "claude-sonnet-4-5"},
+		"template":                      {Provider: "template"},
+		"replay":                        {Provider: "replay"},
+	} {
+		got, err := routing.ParseSpec(in)
+		if err != nil || got != want || got.String() != in {
+			t.Errorf("ParseSpec(%q) = %+v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"ollama:llama3", "openai", "openai:", "template:x", "replay:gpt-4o"} {
+		if _, err := routing.ParseSpec(in); err == nil {
+			t.Errorf("ParseSpec(%q) accepted", in)
+		}
+	}
+}
+
+func TestParseCardRange(t *testing.T) {
+	for in, want := range map[string]routing.CardRange{
+		"1-3": {Min: 1, Max: 3},
+		"5":   {Min: 5, Max: 5},
+		"7+":  {Min: 7},
+	} {
+		got, err := routing.ParseCardRange(in)
+		if err != nil || got != want || got.String() != in {
+			t.Errorf("ParseCardRange(%q) = %+v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "0", "3-1", "a+", "1-"} {
+		if _, err := routing.ParseCardRange(in); err == nil {
+			t.Errorf("ParseCardRange(%q) accepted", in)
+		}
+	}
+}